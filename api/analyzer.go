@@ -0,0 +1,7 @@
+package api
+
+// FieldAnalyzer is the text analyzer used by the full text index of a search field.
+type FieldAnalyzer struct {
+	Field    SearchField `json:"field"`
+	Analyzer string      `json:"analyzer"`
+}
@@ -0,0 +1,12 @@
+package api
+
+// App groups the versions of the same application, identified by their slug.
+type App struct {
+	Slug string `json:"slug"`
+	// Title of the latest version.
+	Title         string `json:"title"`
+	LatestVersion string `json:"latestVersion"`
+	// LatestID is the id of the record of the latest version.
+	LatestID string `json:"latestId"`
+	Versions int    `json:"versions"`
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"time"
+)
+
+type ChangeOp string
+
+const (
+	// Change operation enum values.
+	ChangeOpCreate = "create"
+	ChangeOpUpdate = "update"
+	ChangeOpDelete = "delete"
+)
+
+// IsValid determines if the instance of ChangeOp is one of the valid enum values.
+// NOTE: This implementation is not ideal because a bug could be introduced
+// if a new value is introduced and it is not added to this function.
+// This is a workaround to the lack of enums in go.
+func (op ChangeOp) IsValid() error {
+	switch op {
+	case ChangeOpCreate, ChangeOpUpdate, ChangeOpDelete:
+		return nil
+	}
+	return errors.New("invalid change operation type")
+}
+
+// Change describes a single mutation of the stored records.
+type Change struct {
+	// Seq is a monotonically increasing sequence number that identifies the change.
+	Seq      uint64   `json:"seq"`
+	Op       ChangeOp `json:"op"`
+	RecordID string   `json:"recordId"`
+	// Revision is the revision of the record created by the change.
+	Revision  int       `json:"revision"`
+	Author    string    `json:"author"`
+	Timestamp time.Time `json:"timestamp"`
+	// Record is the state of the record after the change, nil for deletes.
+	Record *MetaRecord `json:"-"`
+}
+
+// Revision is an immutable version of a record created by a change.
+type Revision struct {
+	// Revision numbers start at 1 and increase by one with every change of the record.
+	Revision  int       `json:"revision"`
+	Op        ChangeOp  `json:"op"`
+	Author    string    `json:"author"`
+	Timestamp time.Time `json:"timestamp"`
+	// Record is the state of the record after the change, nil for deletes.
+	Record *MetaRecord `json:"-"`
+}
+
+// FieldDiff is the difference of a single top level field between two revisions of a record.
+// Values are yaml encoded, a missing value means the field was not present.
+type FieldDiff struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
@@ -0,0 +1,26 @@
+package api
+
+// ComplianceStatus is the outcome of evaluating a license against the license policy.
+type ComplianceStatus string
+
+const (
+	ComplianceStatusAllowed     ComplianceStatus = "allowed"
+	ComplianceStatusNeedsReview ComplianceStatus = "needsReview"
+	ComplianceStatusDenied      ComplianceStatus = "denied"
+)
+
+// LicenseCompliance is the status of a single license of an expression.
+type LicenseCompliance struct {
+	License string           `json:"license"`
+	Status  ComplianceStatus `json:"status"`
+}
+
+// ComplianceReport explains how the license policy applies to a record.
+type ComplianceReport struct {
+	ID      string `json:"id"`
+	License string `json:"license"`
+	// Status is the status of the whole expression: an OR expression takes the best status
+	// of its alternatives and an AND expression the worst status of its components.
+	Status   ComplianceStatus    `json:"status"`
+	Licenses []LicenseCompliance `json:"licenses"`
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"time"
+)
+
+type CustomFieldType string
+
+const (
+	// Custom field type enum values.
+	CustomFieldTypeString  = "string"
+	CustomFieldTypeNumber  = "number"
+	CustomFieldTypeBoolean = "boolean"
+
+	// Custom field index enum values, custom fields are not indexed if empty.
+	CustomFieldIndexExact    = "exact"
+	CustomFieldIndexFullText = "fullText"
+	CustomFieldIndexBoth     = "both"
+)
+
+// IsValid determines if the instance of CustomFieldType is one of the valid enum values.
+func (t CustomFieldType) IsValid() error {
+	switch t {
+	case CustomFieldTypeString, CustomFieldTypeNumber, CustomFieldTypeBoolean:
+		return nil
+	}
+	return errors.New("invalid custom field type")
+}
+
+// CustomField is an additional top level field of the records schema registered at runtime.
+type CustomField struct {
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Required bool            `json:"required"`
+	// Validation is a rule in the syntax of the validate struct tags, e.g. "email" or "oneof=gold silver".
+	Validation string `json:"validation,omitempty"`
+	// Index is the kind of index used to search the field, it is not searchable if empty.
+	// Fields indexed both ways are matched exactly unless a search term selects fullText.
+	Index     string    `json:"index,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,11 @@
+package api
+
+// MaintainerProfile aggregates the records a maintainer appears in, maintainers are
+// identified by their normalized email.
+type MaintainerProfile struct {
+	Email string `json:"email"`
+	// Names are the distinct names the maintainer appears with, sorted.
+	Names []string `json:"names"`
+	// RecordIDs are the ids of the records the maintainer appears in, sorted.
+	RecordIDs []string `json:"recordIds"`
+}
@@ -1,11 +1,18 @@
 package api
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type SearchField string
 
 type SearchJoinMethod string
 
+type SearchMode string
+
+type MatchMode string
+
 const (
 	// Field enum values.
 	SearchFieldTitle           = "title"
@@ -17,10 +24,32 @@ const (
 	SearchFieldSource          = "source"
 	SearchFieldLicense         = "license"
 	SearchFieldDescription     = "description"
+	// Labels are queried with label selectors, e.g. "tier=critical,team!=legacy".
+	SearchFieldLabels = "labels"
+	// Compliance is the status of the license of the record according to the license
+	// policy, see ComplianceStatus.
+	SearchFieldCompliance = "compliance"
+	// The host of the source url and the owner of the repository, which is only known
+	// for repositories hosted in GitHub, GitLab or Bitbucket. Both are case insensitive.
+	SearchFieldSourceHost  = "sourceHost"
+	SearchFieldSourceOwner = "sourceOwner"
+	// Maintainer matches the name and the email of the same maintainer, the query has the
+	// form "Name <email>".
+	SearchFieldMaintainer = "maintainer"
 
 	// Join method enum values.
 	SearchJoinMethodAND = "and"
 	SearchJoinMethodOR  = "or"
+
+	// Search mode enum values.
+	// Strict searches fail if any of the terms can't be resolved, lenient searches skip them.
+	SearchModeStrict  = "strict"
+	SearchModeLenient = "lenient"
+
+	// Match mode enum values.
+	// They select the index used by a search term when its field is indexed both ways.
+	MatchModeExact    = "exact"
+	MatchModeFullText = "fullText"
 )
 
 // With no native enums in Go the following 2 functions are decent validation methods.
@@ -40,12 +69,29 @@ func (f SearchField) IsValid() error {
 		SearchFieldTitle,
 		SearchFieldVersion,
 		SearchFieldWebsite,
-		SearchFieldDescription:
+		SearchFieldDescription,
+		SearchFieldLabels,
+		SearchFieldCompliance,
+		SearchFieldSourceHost,
+		SearchFieldSourceOwner,
+		SearchFieldMaintainer:
 		return nil
 	}
 	return errors.New("invalid search field type")
 }
 
+// IsMultiValued reports whether a record can have more than one value for the field.
+func (f SearchField) IsMultiValued() bool {
+	switch f {
+	case SearchFieldMaintainerEmail,
+		SearchFieldMaintainerName,
+		SearchFieldMaintainer,
+		SearchFieldLabels:
+		return true
+	}
+	return false
+}
+
 // ValidSearchFieldValues returns all the valid values a SearchField can take.
 // NOTE: This implementation is not ideal because a bug could be introduced
 // if a new value is introduced and it is not added to this function.
@@ -61,6 +107,11 @@ func ValidSearchFieldValues() []SearchField {
 		SearchFieldSource,
 		SearchFieldLicense,
 		SearchFieldDescription,
+		SearchFieldLabels,
+		SearchFieldCompliance,
+		SearchFieldSourceHost,
+		SearchFieldSourceOwner,
+		SearchFieldMaintainer,
 	}
 }
 
@@ -76,7 +127,99 @@ func (jm SearchJoinMethod) IsValid() error {
 	return errors.New("invalid join method type")
 }
 
+// IsValid determines if the instance of SearchMode is one of the valid enum values.
+// The zero value is valid and means the default (lenient) mode.
+// NOTE: This implementation is not ideal because a bug could be introduced
+// if a new value is introduced and it is not added to this function.
+// This is a workaround to the lack of enums in go.
+func (m SearchMode) IsValid() error {
+	switch m {
+	case "", SearchModeStrict, SearchModeLenient:
+		return nil
+	}
+	return errors.New("invalid search mode type")
+}
+
+// IsValid determines if the instance of MatchMode is one of the valid enum values.
+// The zero value is valid and means the default index of the field.
+// NOTE: This implementation is not ideal because a bug could be introduced
+// if a new value is introduced and it is not added to this function.
+// This is a workaround to the lack of enums in go.
+func (m MatchMode) IsValid() error {
+	switch m {
+	case "", MatchModeExact, MatchModeFullText:
+		return nil
+	}
+	return errors.New("invalid match mode type")
+}
+
 type SearchTerm struct {
 	Field SearchField `json:"field"`
 	Query string      `json:"query"`
+	// Match selects the exact or the full text index of fields indexed both ways, terms
+	// of fields with a single index fail if it doesn't match their kind.
+	Match MatchMode `json:"match,omitempty"`
+}
+
+type SearchRequest struct {
+	JoinMethod  SearchJoinMethod `json:"joinMethod"`
+	SearchTerms []SearchTerm     `json:"searchTerms"`
+	// Mode determines what happens when a term can't be resolved, defaults to lenient.
+	Mode SearchMode `json:"mode,omitempty"`
+	// Explain adds a breakdown of how every term was resolved to the response.
+	Explain bool `json:"explain,omitempty"`
+	// LatestOnly keeps only the highest version of every application among the matches.
+	LatestOnly bool `json:"latestOnly,omitempty"`
+}
+
+// FieldValue is a distinct value that has been indexed for a search field
+// along with the number of records that contain it.
+type FieldValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchExplanation describes how a search was resolved.
+type SearchExplanation struct {
+	JoinMethod SearchJoinMethod  `json:"joinMethod"`
+	Terms      []TermExplanation `json:"terms"`
+}
+
+// TermExplanation describes how a single search term was resolved and how it
+// affected the final result set.
+type TermExplanation struct {
+	Field SearchField `json:"field"`
+	Query string      `json:"query"`
+	// Index is the kind of index used to resolve the term: exact or fullText.
+	Index string `json:"index"`
+	// NormalizedQuery is the query as it was looked up by the index.
+	NormalizedQuery string `json:"normalizedQuery"`
+	Hits            int    `json:"hits"`
+	Error           string `json:"error,omitempty"`
+	// Remaining is the size of the result set after joining this term with all the previous ones.
+	Remaining int `json:"remaining"`
+}
+
+// SearchWarning describes a search term that was skipped by a lenient search.
+type SearchWarning struct {
+	// Term is the position of the skipped term in the search request.
+	Term    int         `json:"term"`
+	Field   SearchField `json:"field"`
+	Query   string      `json:"query"`
+	Message string      `json:"message"`
+}
+
+// SavedSearch is a named search request that can be executed repeatedly.
+type SavedSearch struct {
+	Name      string        `json:"name"`
+	Request   SearchRequest `json:"request"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Subscription is a search request that is evaluated against every new record.
+type Subscription struct {
+	ID        string        `json:"id"`
+	Request   SearchRequest `json:"request"`
+	CreatedAt time.Time     `json:"createdAt"`
 }
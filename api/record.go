@@ -1,19 +1,126 @@
 package api
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
 
-var ErrFieldLookupNotSupported = errors.New("the lookup of this search field is not supported")
+	"github.com/goccy/go-yaml"
+)
+
+var (
+	ErrFieldLookupNotSupported = errors.New("the lookup of this search field is not supported")
+	// ErrMultiValuedField is returned when looking up a single value of a field that can have many.
+	ErrMultiValuedField = fmt.Errorf("%w: the search field can have multiple values", ErrFieldLookupNotSupported)
+	// ErrDerivedField is returned for the search fields that are computed by the store
+	// instead of being part of the record.
+	ErrDerivedField = fmt.Errorf("%w: the search field is derived by the store", ErrFieldLookupNotSupported)
+)
+
+const (
+	// APIVersionV1 is the current version of the records schema, records without an
+	// apiVersion are considered to be of this version.
+	APIVersionV1 = "v1"
+	// APIVersionV1Alpha1 is the draft version of the schema, it is upgraded to v1.
+	APIVersionV1Alpha1 = "v1alpha1"
+)
 
 type MetaRecord struct {
-	Title   string `yaml:"title" validate:"required"`
-	Version string `yaml:"version" validate:"required"`
+	// ID and Revision are assigned by the store, they are not part of the yaml document.
+	ID       string `yaml:"-"`
+	Revision int    `yaml:"-"`
+	// APIVersion is the version of the schema, records of previous versions are upgraded
+	// when they are added so it is always the current version if present.
+	APIVersion string `yaml:"apiVersion,omitempty" validate:"omitempty,oneof=v1"`
+	Title      string `yaml:"title" validate:"required"`
+	Version    string `yaml:"version" validate:"required"`
+	// Slug groups the versions of the same application, it is derived from the title if empty.
+	Slug string `yaml:"slug,omitempty" validate:"omitempty,slug"`
 	// dive tag option is necessary to validate fields in the nested struct.
 	Maintainers []maintainer `yaml:"maintainers" validate:"required,gt=0,dive"`
 	Company     string       `yaml:"company" validate:"required"`
 	Website     string       `yaml:"website" validate:"required,url"`
 	Source      string       `yaml:"source" validate:"required,url"`
-	License     string       `yaml:"license" validate:"required"`
+	License     string       `yaml:"license" validate:"required,spdx"`
 	Description string       `yaml:"description" validate:"required"`
+	// Labels are free form key value pairs that can be queried with label selectors.
+	Labels map[string]string `yaml:"labels,omitempty" validate:"omitempty,dive,keys,labelkey,endkeys,labelvalue"`
+	// Annotations hold arbitrary non identifying metadata, they are not indexed.
+	Annotations map[string]string `yaml:"annotations,omitempty" validate:"omitempty,dive,keys,labelkey,endkeys"`
+	// Custom holds the top level fields of the yaml document that are not part of the
+	// schema above, they are validated by the custom fields registered in the store.
+	Custom map[string]interface{} `yaml:"-"`
+}
+
+// recordFields are the top level keys of the yaml document handled by MetaRecord's fields.
+var recordFields = func() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(MetaRecord{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// IsRecordField reports whether name is a top level field of the fixed records schema.
+func IsRecordField(name string) bool {
+	return recordFields[name]
+}
+
+// plainRecord has the same fields as MetaRecord without its yaml methods.
+type plainRecord MetaRecord
+
+// UnmarshalYAML decodes the fields of the schema and keeps every other top level field in Custom.
+func (r *MetaRecord) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal((*plainRecord)(r)); err != nil {
+		return err
+	}
+	var doc yaml.MapSlice
+	if err := unmarshal(&doc); err != nil {
+		return err
+	}
+	for _, item := range doc {
+		key, ok := item.Key.(string)
+		if !ok || recordFields[key] {
+			continue
+		}
+		if r.Custom == nil {
+			r.Custom = map[string]interface{}{}
+		}
+		r.Custom[key] = item.Value
+	}
+	return nil
+}
+
+// MarshalYAML encodes the custom fields after the fields of the schema, sorted by name.
+func (r MetaRecord) MarshalYAML() (interface{}, error) {
+	if len(r.Custom) == 0 {
+		return plainRecord(r), nil
+	}
+	raw, err := yaml.Marshal(plainRecord(r))
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(raw, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(r.Custom))
+	for name := range r.Custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc = append(doc, yaml.MapItem{Key: name, Value: r.Custom[name]})
+	}
+	return doc, nil
 }
 
 type maintainer struct {
@@ -21,33 +128,112 @@ type maintainer struct {
 	Email string `yaml:"email" validate:"required,email"`
 }
 
-// fieldValueFromSearchField returns the struct field value from a given SearchField.
+// String returns the maintainer in the "Name <email>" form.
+func (m maintainer) String() string {
+	return m.Name + " <" + m.Email + ">"
+}
+
+// FieldValueFromSearchField returns the value of a single valued search field.
+// Fields that can have multiple values return ErrMultiValuedField, use FieldValues instead.
+func (r *MetaRecord) FieldValueFromSearchField(field SearchField) (string, error) {
+	if field.IsMultiValued() {
+		return "", ErrMultiValuedField
+	}
+	values, err := r.FieldValues(field)
+	if err != nil {
+		return "", err
+	}
+	return values[0], nil
+}
+
+// FieldValues returns every value of a search field, in the order they appear in the record.
+// Single valued fields return exactly one value. Maintainers are formatted as "Name <email>"
+// and labels as "key=value", sorted by key.
 // NOTE: The reflection api could probably be used to make this implementations simpler,
 // but I'm pretty sure that's not the purpose of that api.
 // This implementation is flaky because any new value of SearchField means this
 // function has to be updated and there's no mechanism to produce a compilation
 // error if we forget to add it.
-func (r *MetaRecord) FieldValueFromSearchField(field SearchField) (string, error) {
+func (r *MetaRecord) FieldValues(field SearchField) ([]string, error) {
+	switch field {
+	case SearchFieldMaintainerEmail, SearchFieldMaintainerName, SearchFieldMaintainer:
+		values := make([]string, 0, len(r.Maintainers))
+		for _, m := range r.Maintainers {
+			switch field {
+			case SearchFieldMaintainerEmail:
+				values = append(values, m.Email)
+			case SearchFieldMaintainerName:
+				values = append(values, m.Name)
+			default:
+				values = append(values, m.String())
+			}
+		}
+		return values, nil
+	case SearchFieldLabels:
+		keys := make([]string, 0, len(r.Labels))
+		for key := range r.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, key+"="+r.Labels[key])
+		}
+		return values, nil
+	case SearchFieldCompliance, SearchFieldSourceHost, SearchFieldSourceOwner:
+		return nil, ErrDerivedField
+	}
 
+	value, ok := r.singleValue(field)
+	if !ok {
+		return nil, errors.New("invalid search field type")
+	}
+	return []string{value}, nil
+}
+
+func (r *MetaRecord) singleValue(field SearchField) (string, bool) {
 	switch field {
 	case SearchFieldCompany:
-		return r.Company, nil
+		return r.Company, true
 	case SearchFieldLicense:
-		return r.License, nil
-	case SearchFieldMaintainerEmail:
-		return "", ErrFieldLookupNotSupported
-	case SearchFieldMaintainerName:
-		return "", ErrFieldLookupNotSupported
+		return r.License, true
 	case SearchFieldSource:
-		return r.Source, nil
+		return r.Source, true
 	case SearchFieldTitle:
-		return r.Title, nil
+		return r.Title, true
 	case SearchFieldVersion:
-		return r.Version, nil
+		return r.Version, true
 	case SearchFieldWebsite:
-		return r.Website, nil
+		return r.Website, true
 	case SearchFieldDescription:
-		return r.Description, nil
+		return r.Description, true
+	}
+	return "", false
+}
+
+// AppSlug returns the slug of the application the record is a version of.
+func (r *MetaRecord) AppSlug() string {
+	if r.Slug != "" {
+		return r.Slug
+	}
+	return Slugify(r.Title)
+}
+
+// Slugify lowercases a title and replaces every run of characters that are not letters
+// or digits with a single hyphen, e.g. "Valid App 1" becomes "valid-app-1".
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, c := range strings.ToLower(title) {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			pendingHyphen = b.Len() > 0
+			continue
+		}
+		if pendingHyphen {
+			b.WriteRune('-')
+			pendingHyphen = false
+		}
+		b.WriteRune(c)
 	}
-	return "", nil
+	return b.String()
 }
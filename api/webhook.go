@@ -0,0 +1,24 @@
+package api
+
+import "time"
+
+// Webhook is an outbound url that receives a request for every record change.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events restricts the operations the webhook is notified of, empty means all of them.
+	Events    []ChangeOp `json:"events"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// DeadLetter is a webhook delivery that failed after exhausting all of its retries.
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhookId"`
+	URL       string    `json:"url"`
+	ChangeSeq uint64    `json:"changeSeq"`
+	Payload   string    `json:"payload"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
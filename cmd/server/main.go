@@ -1,15 +1,97 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/AYM1607/goAKSChallenge/api"
 	"github.com/AYM1607/goAKSChallenge/internal/server"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
 )
 
 func main() {
-	srvr, err := server.NewServer(":8888")
+	naturalKey := flag.String("natural-key", "title,version",
+		"comma separated fields that identify a record, empty to allow duplicates")
+	idempotencyTTL := flag.Duration("idempotency-ttl", 24*time.Hour,
+		"how long the responses of requests with an Idempotency-Key header are kept")
+	licenseAllow := flag.String("license-allow", "",
+		"comma separated licenses that are allowed, empty to allow every license that is not denied or needs review")
+	licenseDeny := flag.String("license-deny", "", "comma separated licenses that are rejected")
+	licenseReview := flag.String("license-review", "", "comma separated licenses that are flagged for review")
+	websiteSchemes := flag.String("website-schemes", "http,https", "comma separated schemes allowed in websites, empty to allow any")
+	websiteHosts := flag.String("website-hosts", "", "comma separated hosts allowed in websites, *.example.com allows subdomains, empty to allow any")
+	sourceSchemes := flag.String("source-schemes", "http,https", "comma separated schemes allowed in sources, empty to allow any")
+	sourceHosts := flag.String("source-hosts", "", "comma separated hosts allowed in sources, *.example.com allows subdomains, empty to allow any")
+	stripMarkdown := flag.Bool("strip-markdown", false,
+		"remove the markdown syntax of descriptions before adding them to the full text index")
+	analyzers := flag.String("analyzers", "",
+		"comma separated field=analyzer pairs setting the analyzers of full text fields, one of "+
+			strings.Join(store.Analyzers(), ","))
+	indexStrategies := flag.String("index-strategies", "",
+		"comma separated field=strategy pairs setting how fields are indexed, one of exact,fullText,both")
+	flag.Parse()
+
+	fields := []api.SearchField{}
+	for _, field := range splitList(*naturalKey) {
+		fields = append(fields, api.SearchField(field))
+	}
+	policy := store.LicensePolicy{
+		Allowed:     splitList(*licenseAllow),
+		Denied:      splitList(*licenseDeny),
+		NeedsReview: splitList(*licenseReview),
+	}
+
+	storeOpts := []store.Option{
+		store.WithNaturalKey(fields...),
+		store.WithLicensePolicy(policy),
+		store.WithURLRule(api.SearchFieldWebsite, store.URLRule{
+			Schemes: splitList(*websiteSchemes),
+			Hosts:   splitList(*websiteHosts),
+		}),
+		store.WithURLRule(api.SearchFieldSource, store.URLRule{
+			Schemes: splitList(*sourceSchemes),
+			Hosts:   splitList(*sourceHosts),
+		}),
+	}
+	for _, pair := range splitList(*indexStrategies) {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid index strategy %q, expected field=strategy", pair)
+		}
+		field := api.SearchField(strings.TrimSpace(parts[0]))
+		storeOpts = append(storeOpts, store.WithIndexStrategy(field, store.IndexStrategy(strings.TrimSpace(parts[1]))))
+	}
+	for _, pair := range splitList(*analyzers) {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid analyzer %q, expected field=analyzer", pair)
+		}
+		field := api.SearchField(strings.TrimSpace(parts[0]))
+		storeOpts = append(storeOpts, store.WithAnalyzer(field, strings.TrimSpace(parts[1])))
+	}
+	if *stripMarkdown {
+		storeOpts = append(storeOpts, store.WithMarkdownStripping())
+	}
+
+	srvr, err := server.NewServer(":8888",
+		server.WithStoreOptions(storeOpts...),
+		server.WithIdempotencyTTL(*idempotencyTTL),
+	)
 	if err != nil {
-		log.Fatal("Server could not be created")
+		log.Fatalf("Server could not be created: %s", err)
 	}
 	log.Fatal(srvr.ListenAndServe())
 }
+
+// splitList splits a comma separated flag, ignoring empty items.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
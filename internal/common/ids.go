@@ -0,0 +1,28 @@
+package common
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	// The same monotonic entropy must be used for every id so ids created within the
+	// same millisecond are still sorted by creation.
+	entropyMu sync.Mutex
+	entropy   = ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0)
+)
+
+// NewID creates a string parsable, lexicographically sortable unique identifier.
+func NewID() (string, error) {
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+
+	id, err := ulid.New(ulid.Timestamp(time.Now()), entropy)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
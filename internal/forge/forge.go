@@ -0,0 +1,74 @@
+// Package forge recognizes the repository URLs of the known code forges.
+package forge
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+)
+
+// forges maps the host of every known forge to its name.
+var forges = map[string]string{
+	"github.com":    GitHub,
+	"gitlab.com":    GitLab,
+	"bitbucket.org": Bitbucket,
+}
+
+// Repository is a repository hosted in a known forge.
+type Repository struct {
+	Forge string
+	Host  string
+	// Owner is the user or organization the repository belongs to. GitLab projects can
+	// belong to nested groups, in which case the owner is the full path of the group.
+	Owner string
+	Name  string
+}
+
+// Parse extracts the repository of a URL of a known forge. URLs of pages inside the
+// repository (e.g. https://github.com/owner/repo/tree/main) are accepted. Returns false if
+// the URL doesn't belong to a known forge or doesn't point to a repository.
+func Parse(rawURL string) (Repository, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Repository{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	forge, ok := forges[host]
+	if !ok {
+		return Repository{}, false
+	}
+
+	segments := []string{}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if forge == GitLab {
+		// GitLab separates the project path from its pages with a "-" segment.
+		for i, segment := range segments {
+			if segment == "-" {
+				segments = segments[:i]
+				break
+			}
+		}
+	} else if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	if len(segments) < 2 {
+		return Repository{}, false
+	}
+
+	last := len(segments) - 1
+	return Repository{
+		Forge: forge,
+		Host:  host,
+		Owner: strings.Join(segments[:last], "/"),
+		Name:  strings.TrimSuffix(segments[last], ".git"),
+	}, true
+}
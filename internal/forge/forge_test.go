@@ -0,0 +1,27 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for rawURL, expected := range map[string]Repository{
+		"https://github.com/upbound/repo":                {Forge: GitHub, Host: "github.com", Owner: "upbound", Name: "repo"},
+		"https://www.GitHub.com/upbound/repo.git":        {Forge: GitHub, Host: "github.com", Owner: "upbound", Name: "repo"},
+		"https://github.com/upbound/repo/tree/main/docs": {Forge: GitHub, Host: "github.com", Owner: "upbound", Name: "repo"},
+		"https://gitlab.com/group/subgroup/project":      {Forge: GitLab, Host: "gitlab.com", Owner: "group/subgroup", Name: "project"},
+		"https://gitlab.com/group/project/-/tree/main":   {Forge: GitLab, Host: "gitlab.com", Owner: "group", Name: "project"},
+		"https://bitbucket.org/team/repo/src/master/":    {Forge: Bitbucket, Host: "bitbucket.org", Owner: "team", Name: "repo"},
+	} {
+		repository, ok := Parse(rawURL)
+		require.True(t, ok, rawURL)
+		require.Equal(t, expected, repository, rawURL)
+	}
+
+	for _, rawURL := range []string{"https://example.com/owner/repo", "https://github.com/upbound", "https://github.com", "::"} {
+		_, ok := Parse(rawURL)
+		require.False(t, ok, rawURL)
+	}
+}
@@ -0,0 +1,236 @@
+package license
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidExpression = errors.New("invalid SPDX license expression")
+
+const (
+	OpAnd  = "AND"
+	OpOr   = "OR"
+	opWith = "WITH"
+)
+
+// licenseRefRegexp matches user defined licenses, which are not part of the SPDX list.
+var licenseRefRegexp = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?LicenseRef-[A-Za-z0-9.-]+$`)
+
+// Expression is a parsed SPDX license expression.
+type Expression interface {
+	// String returns the canonical form of the expression.
+	String() string
+}
+
+// License is a single license of an expression, optionally with an exception.
+type License struct {
+	ID string
+	// OrLater is true for licenses followed by "+".
+	OrLater   bool
+	Exception string
+}
+
+func (l License) String() string {
+	s := l.ID
+	if l.OrLater {
+		s += "+"
+	}
+	if l.Exception != "" {
+		s += " " + opWith + " " + l.Exception
+	}
+	return s
+}
+
+// Compound joins two or more expressions with the same operator.
+type Compound struct {
+	Op    string
+	Terms []Expression
+}
+
+func (c Compound) String() string {
+	terms := make([]string, 0, len(c.Terms))
+	for _, term := range c.Terms {
+		s := term.String()
+		// AND has a higher precedence than OR so only OR expressions nested in AND
+		// expressions need parentheses.
+		if nested, ok := term.(Compound); ok && c.Op == OpAnd && nested.Op == OpOr {
+			s = "(" + s + ")"
+		}
+		terms = append(terms, s)
+	}
+	return strings.Join(terms, " "+c.Op+" ")
+}
+
+// Licenses returns every license of an expression in the order they appear.
+func Licenses(expr Expression) []License {
+	switch e := expr.(type) {
+	case License:
+		return []License{e}
+	case Compound:
+		licenses := []License{}
+		for _, term := range e.Terms {
+			licenses = append(licenses, Licenses(term)...)
+		}
+		return licenses
+	}
+	return nil
+}
+
+// Canonicalize returns the canonical form of an SPDX expression: identifiers with the case
+// of the SPDX list, uppercase operators and only the required parentheses.
+func Canonicalize(expression string) (string, error) {
+	expr, err := Parse(expression)
+	if err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+// Parse parses an SPDX license expression, validating its identifiers against the bundled
+// SPDX lists. Operators are accepted in any case.
+func Parse(expression string) (Expression, error) {
+	p := &parser{tokens: tokenize(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("%w: the expression is empty", ErrInvalidExpression)
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidExpression, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+func tokenize(expression string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, c := range expression {
+		switch {
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token without consuming it, empty at the end of the expression.
+func (p *parser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) isOperator(op string) bool {
+	return strings.ToUpper(p.next()) == op
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	return p.parseCompound(OpOr, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	return p.parseCompound(OpAnd, p.parseWith)
+}
+
+// parseCompound parses terms joined by op, nested expressions with the same operator are
+// flattened since both operators are associative.
+func (p *parser) parseCompound(op string, parseTerm func() (Expression, error)) (Expression, error) {
+	terms := []Expression{}
+	for {
+		term, err := parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if nested, ok := term.(Compound); ok && nested.Op == op {
+			terms = append(terms, nested.Terms...)
+		} else {
+			terms = append(terms, term)
+		}
+		if !p.isOperator(op) {
+			break
+		}
+		p.pos += 1
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return Compound{Op: op, Terms: terms}, nil
+}
+
+func (p *parser) parseWith() (Expression, error) {
+	if p.next() == "(" {
+		p.pos += 1
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidExpression)
+		}
+		p.pos += 1
+		return expr, nil
+	}
+
+	license, err := p.parseLicense()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator(opWith) {
+		p.pos += 1
+		exception, ok := LookupException(p.next())
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown license exception %q", ErrInvalidExpression, p.next())
+		}
+		p.pos += 1
+		license.Exception = exception
+	}
+	return license, nil
+}
+
+func (p *parser) parseLicense() (License, error) {
+	token := p.next()
+	switch {
+	case token == "" || token == ")" || p.isOperator(OpAnd) || p.isOperator(OpOr) || p.isOperator(opWith):
+		if token == "" {
+			return License{}, fmt.Errorf("%w: unexpected end of the expression", ErrInvalidExpression)
+		}
+		return License{}, fmt.Errorf("%w: expected a license but found %q", ErrInvalidExpression, token)
+	}
+	p.pos += 1
+
+	if licenseRefRegexp.MatchString(token) {
+		return License{ID: token}, nil
+	}
+	// Some deprecated identifiers (e.g. GPL-2.0+) include the plus sign.
+	if id, ok := LookupLicense(token); ok {
+		return License{ID: id}, nil
+	}
+	if base := strings.TrimSuffix(token, "+"); base != token {
+		if id, ok := LookupLicense(base); ok {
+			return License{ID: id, OrLater: true}, nil
+		}
+	}
+	return License{}, fmt.Errorf("%w: unknown license %q", ErrInvalidExpression, token)
+}
@@ -0,0 +1,41 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for expression, expected := range map[string]string{
+		"apache-2.0":                                    "Apache-2.0",
+		"mit or Apache-2.0":                             "MIT OR Apache-2.0",
+		"(MIT OR Apache-2.0) and BSD-3-Clause":          "(MIT OR Apache-2.0) AND BSD-3-Clause",
+		"MIT AND (Apache-2.0 AND 0BSD)":                 "MIT AND Apache-2.0 AND 0BSD",
+		"(MIT AND 0BSD) OR Apache-2.0":                  "MIT AND 0BSD OR Apache-2.0",
+		"gpl-2.0-or-later with classpath-exception-2.0": "GPL-2.0-or-later WITH Classpath-exception-2.0",
+		"LGPL-2.1+":                                     "LGPL-2.1+",
+		"GPL-2.0+":                                      "GPL-2.0+",
+		"LicenseRef-Proprietary":                        "LicenseRef-Proprietary",
+		"(MIT)":                                         "MIT",
+	} {
+		canonical, err := Canonicalize(expression)
+		require.NoError(t, err, expression)
+		require.Equal(t, expected, canonical, expression)
+	}
+
+	for _, invalid := range []string{"", "Apache 2", "MIT OR", "AND MIT", "(MIT", "MIT)", "MIT WITH Apache-2.0", "MIT Apache-2.0"} {
+		_, err := Canonicalize(invalid)
+		require.ErrorIs(t, err, ErrInvalidExpression, invalid)
+	}
+}
+
+func TestLicenses(t *testing.T) {
+	expr, err := Parse("(MIT OR Apache-2.0) AND GPL-2.0-or-later WITH Classpath-exception-2.0")
+	require.NoError(t, err)
+	require.Equal(t, []License{
+		{ID: "MIT"},
+		{ID: "Apache-2.0"},
+		{ID: "GPL-2.0-or-later", Exception: "Classpath-exception-2.0"},
+	}, Licenses(expr))
+}
@@ -0,0 +1,47 @@
+// Package license validates and canonicalizes SPDX license expressions.
+package license
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+)
+
+var (
+	//go:embed spdx/licenses.txt
+	rawLicenses string
+	//go:embed spdx/exceptions.txt
+	rawExceptions string
+
+	// licenses and exceptions map the lowercased identifiers to their canonical form,
+	// SPDX identifiers are matched case insensitively.
+	licenses   = parseList(rawLicenses)
+	exceptions = parseList(rawExceptions)
+)
+
+// parseList reads a bundled identifiers list, see the header of the files for the format.
+func parseList(raw string) map[string]string {
+	ids := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id := strings.Fields(line)[0]
+		ids[strings.ToLower(id)] = id
+	}
+	return ids
+}
+
+// LookupLicense returns the canonical form of an SPDX license identifier.
+func LookupLicense(id string) (string, bool) {
+	canonical, ok := licenses[strings.ToLower(id)]
+	return canonical, ok
+}
+
+// LookupException returns the canonical form of an SPDX license exception identifier.
+func LookupException(id string) (string, bool) {
+	canonical, ok := exceptions[strings.ToLower(id)]
+	return canonical, ok
+}
@@ -0,0 +1,400 @@
+// Package markdown renders the subset of markdown used in descriptions: headings,
+// paragraphs, emphasis, code, links, lists, block quotes and thematic breaks.
+// The output is sanitized by construction, raw HTML in the source is escaped and only
+// the tags of the supported elements are ever produced.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRegexp  = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	breakRegexp    = regexp.MustCompile(`^(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	listItemRegexp = regexp.MustCompile(`^\s{0,3}([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	languageRegexp = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+)
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockCode
+	blockList
+	blockQuote
+	blockBreak
+)
+
+type block struct {
+	kind blockKind
+	// text of paragraphs and headings.
+	text  string
+	level int
+	// code blocks.
+	language string
+	lines    []string
+	// lists.
+	ordered bool
+	items   []string
+	// block quotes.
+	children []block
+}
+
+// ToHTML renders markdown to HTML.
+func ToHTML(src string) string {
+	var b strings.Builder
+	renderHTML(&b, parse(lines(src)))
+	return b.String()
+}
+
+// ToText removes the markdown syntax, keeping only the text that would be displayed.
+// Blocks are separated by new lines.
+func ToText(src string) string {
+	texts := []string{}
+	collectText(&texts, parse(lines(src)))
+	return strings.Join(texts, "\n")
+}
+
+func lines(src string) []string {
+	return strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+}
+
+// startsBlock reports whether a line interrupts a paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return headingRegexp.MatchString(trimmed) || breakRegexp.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, ">") || isFence(trimmed) || listItemRegexp.MatchString(line)
+}
+
+func isFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+func parse(lines []string) []block {
+	blocks := []block{}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+		case isFence(trimmed):
+			fence := trimmed[:3]
+			code := block{kind: blockCode, language: strings.TrimSpace(trimmed[3:]), lines: []string{}}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code.lines = append(code.lines, lines[i])
+			}
+			// Skip the closing fence, unclosed blocks run until the end of the document.
+			i++
+			blocks = append(blocks, code)
+		case headingRegexp.MatchString(trimmed):
+			match := headingRegexp.FindStringSubmatch(trimmed)
+			blocks = append(blocks, block{kind: blockHeading, level: len(match[1]), text: match[2]})
+			i++
+		// Thematic breaks are checked before lists because "- - -" is both.
+		case breakRegexp.MatchString(trimmed):
+			blocks = append(blocks, block{kind: blockBreak})
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			quoted := []string{}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			blocks = append(blocks, block{kind: blockQuote, children: parse(quoted)})
+		case listItemRegexp.MatchString(line):
+			list := block{kind: blockList, ordered: !strings.ContainsAny(listItemRegexp.FindStringSubmatch(line)[1], "-*+")}
+			for i < len(lines) {
+				if match := listItemRegexp.FindStringSubmatch(lines[i]); match != nil {
+					list.items = append(list.items, match[2])
+				} else if strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]) {
+					// Lazy continuation of the previous item.
+					list.items[len(list.items)-1] += "\n" + strings.TrimSpace(lines[i])
+				} else {
+					break
+				}
+				i++
+			}
+			blocks = append(blocks, list)
+		default:
+			paragraph := []string{trimmed}
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]); i++ {
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+			}
+			blocks = append(blocks, block{kind: blockParagraph, text: strings.Join(paragraph, "\n")})
+		}
+	}
+	return blocks
+}
+
+func renderHTML(b *strings.Builder, blocks []block) {
+	for _, blk := range blocks {
+		switch blk.kind {
+		case blockParagraph:
+			b.WriteString("<p>" + inline(blk.text, true) + "</p>\n")
+		case blockHeading:
+			tag := "h" + string(rune('0'+blk.level))
+			b.WriteString("<" + tag + ">" + inline(blk.text, true) + "</" + tag + ">\n")
+		case blockCode:
+			b.WriteString("<pre><code")
+			if languageRegexp.MatchString(blk.language) {
+				b.WriteString(` class="language-` + blk.language + `"`)
+			}
+			b.WriteString(">")
+			for _, line := range blk.lines {
+				b.WriteString(html.EscapeString(line) + "\n")
+			}
+			b.WriteString("</code></pre>\n")
+		case blockList:
+			tag := "ul"
+			if blk.ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for _, item := range blk.items {
+				b.WriteString("<li>" + inline(item, true) + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+		case blockQuote:
+			b.WriteString("<blockquote>\n")
+			renderHTML(b, blk.children)
+			b.WriteString("</blockquote>\n")
+		case blockBreak:
+			b.WriteString("<hr>\n")
+		}
+	}
+}
+
+func collectText(texts *[]string, blocks []block) {
+	for _, blk := range blocks {
+		switch blk.kind {
+		case blockParagraph, blockHeading:
+			*texts = append(*texts, inline(blk.text, false))
+		case blockCode:
+			*texts = append(*texts, blk.lines...)
+		case blockList:
+			for _, item := range blk.items {
+				*texts = append(*texts, inline(item, false))
+			}
+		case blockQuote:
+			collectText(texts, blk.children)
+		}
+	}
+}
+
+// inline renders the inline elements of a text, as HTML or as plain text.
+func inline(s string, asHTML bool) string {
+	var b strings.Builder
+	text := func(t string) {
+		if asHTML {
+			t = html.EscapeString(t)
+		}
+		b.WriteString(t)
+	}
+	tag := func(name string, content string) {
+		if asHTML {
+			content = "<" + name + ">" + content + "</" + name + ">"
+		}
+		b.WriteString(content)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()!#>-+.", s[i+1]) >= 0:
+			text(s[i+1 : i+2])
+			i += 2
+			continue
+		case c == '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			delim := s[i : i+n]
+			if closing := strings.Index(s[i+n:], delim); closing >= 0 {
+				code := s[i+n : i+n+closing]
+				if asHTML {
+					code = html.EscapeString(code)
+				}
+				tag("code", code)
+				i += 2*n + closing
+				continue
+			}
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			// Images are not embedded so rendered descriptions can't load remote content,
+			// only their alternative text is kept.
+			if label, _, end, ok := parseLink(s, i+1); ok {
+				text(label)
+				i = end
+				continue
+			}
+		case c == '[':
+			if label, dest, end, ok := parseLink(s, i); ok {
+				content := inline(label, asHTML)
+				if asHTML && isSafeURL(dest) {
+					content = `<a href="` + html.EscapeString(dest) + `" rel="nofollow noopener">` + content + "</a>"
+				}
+				b.WriteString(content)
+				i = end
+				continue
+			}
+		case c == '*' || c == '_':
+			if end, content, ok := emphasis(s, i); ok {
+				name := "em"
+				if end-i-len(content) == 4 {
+					name = "strong"
+				}
+				tag(name, inline(content, asHTML))
+				i = end
+				continue
+			}
+		}
+		text(s[i : i+1])
+		i++
+	}
+	return b.String()
+}
+
+// emphasis matches an emphasized span starting at i, returning the index after its closing
+// delimiter and its content. Underscores don't emphasize parts of words (e.g. snake_case).
+func emphasis(s string, i int) (int, string, bool) {
+	c := s[i]
+	n := 1
+	if i+1 < len(s) && s[i+1] == c {
+		n = 2
+	}
+	delim := s[i : i+n]
+	start := i + n
+	if start >= len(s) || s[start] == ' ' || (c == '_' && i > 0 && isWordChar(s[i-1])) {
+		return 0, "", false
+	}
+	closing := strings.Index(s[start:], delim)
+	if closing <= 0 || s[start+closing-1] == ' ' {
+		return 0, "", false
+	}
+	end := start + closing + n
+	if c == '_' && end < len(s) && isWordChar(s[end]) {
+		return 0, "", false
+	}
+	return end, s[start : start+closing], true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c >= 0x80
+}
+
+// parseLink parses a [label](destination "title") link starting at the bracket in i.
+// Returns the index after the closing parenthesis.
+func parseLink(s string, i int) (string, string, int, bool) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if j+1 >= len(s) || s[j+1] != '(' {
+				return "", "", 0, false
+			}
+			dest, end, ok := parseLinkTail(s, j+2)
+			if !ok {
+				return "", "", 0, false
+			}
+			return s[i+1 : j], dest, end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// parseLinkTail parses the destination and the optional title of a link starting after
+// its opening parenthesis. Destinations can contain balanced parentheses, as in
+// https://en.wikipedia.org/wiki/Go_(language), or be enclosed in angle brackets.
+// Returns the destination and the index after the closing parenthesis.
+func parseLinkTail(s string, i int) (string, int, bool) {
+	i = skipSpaces(s, i)
+	var dest string
+	if i < len(s) && s[i] == '<' {
+		closing := strings.IndexAny(s[i+1:], ">\n")
+		if closing < 0 || s[i+1+closing] != '>' {
+			return "", 0, false
+		}
+		dest, i = s[i+1:i+1+closing], i+2+closing
+	} else {
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case ' ', '\t', '\n':
+				break loop
+			}
+		}
+		if depth > 0 || i > len(s) {
+			return "", 0, false
+		}
+		dest = s[start:i]
+	}
+	if dest == "" {
+		return "", 0, false
+	}
+
+	// The title is not rendered, it only has to be skipped.
+	if next := skipSpaces(s, i); next > i && next < len(s) {
+		var closer byte
+		switch s[next] {
+		case '"', '\'':
+			closer = s[next]
+		case '(':
+			closer = ')'
+		}
+		if closer != 0 {
+			closing := strings.IndexByte(s[next+1:], closer)
+			if closing < 0 {
+				return "", 0, false
+			}
+			i = next + 2 + closing
+		}
+	}
+	i = skipSpaces(s, i)
+	if i >= len(s) || s[i] != ')' {
+		return "", 0, false
+	}
+	return dest, i + 1, true
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// isSafeURL only allows web and mail links, relative links are allowed as well.
+func isSafeURL(dest string) bool {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	case "":
+		// Protocol relative urls could point anywhere.
+		return !strings.HasPrefix(dest, "//")
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToHTML(t *testing.T) {
+	src := "### Interesting Title\n" +
+		"Some **application** content, and _description_\n" +
+		"with `a < b` and a [link](https://example.com \"title\").\n" +
+		"\n" +
+		"- first\n" +
+		"- second\n" +
+		"  continued\n" +
+		"\n" +
+		"1. one\n" +
+		"\n" +
+		"> quoted *text*\n" +
+		"\n" +
+		"```go\n" +
+		"if a < b {}\n" +
+		"```\n" +
+		"---\n"
+	require.Equal(t, "<h3>Interesting Title</h3>\n"+
+		"<p>Some <strong>application</strong> content, and <em>description</em>\n"+
+		"with <code>a &lt; b</code> and a <a href=\"https://example.com\" rel=\"nofollow noopener\">link</a>.</p>\n"+
+		"<ul>\n<li>first</li>\n<li>second\ncontinued</li>\n</ul>\n"+
+		"<ol>\n<li>one</li>\n</ol>\n"+
+		"<blockquote>\n<p>quoted <em>text</em></p>\n</blockquote>\n"+
+		"<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>\n"+
+		"<hr>\n", ToHTML(src))
+}
+
+func TestToHTMLLinks(t *testing.T) {
+	for src, expected := range map[string]string{
+		"[Go](https://en.wikipedia.org/wiki/Go_(language))": "<p><a href=\"https://en.wikipedia.org/wiki/Go_(language)\" rel=\"nofollow noopener\">Go</a></p>\n",
+		"[Go](<https://go.dev/a b> 'the (site)') rocks":     "<p><a href=\"https://go.dev/a b\" rel=\"nofollow noopener\">Go</a> rocks</p>\n",
+		"[Go](https://go.dev (title)).":                     "<p><a href=\"https://go.dev\" rel=\"nofollow noopener\">Go</a>.</p>\n",
+		"[Go](https://go.dev/(unbalanced)":                  "<p>[Go](https://go.dev/(unbalanced)</p>\n",
+	} {
+		require.Equal(t, expected, ToHTML(src), src)
+	}
+}
+
+func TestToHTMLSanitizes(t *testing.T) {
+	for src, expected := range map[string]string{
+		"<script>alert(1)</script>":           "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		"[click](javascript:alert(1))":        "<p>click</p>\n",
+		"[click](JavaScript:alert)":           "<p>click</p>\n",
+		"[click](//evil.example.com)":         "<p>click</p>\n",
+		"[x](https://a.com/\"onclick=\"y)":    "<p><a href=\"https://a.com/&#34;onclick=&#34;y\" rel=\"nofollow noopener\">x</a></p>\n",
+		"![tracker](https://a.com/pixel.gif)": "<p>tracker</p>\n",
+		"```\"><script>\n<b>\n```":            "<pre><code>&lt;b&gt;\n</code></pre>\n",
+		"snake_case_name":                     "<p>snake_case_name</p>\n",
+		"\\*not emphasized\\*":                "<p>*not emphasized*</p>\n",
+	} {
+		require.Equal(t, expected, ToHTML(src), src)
+	}
+}
+
+func TestToText(t *testing.T) {
+	require.Equal(t, "Interesting Title\nSome application content, and link\nfirst\nsecond",
+		ToText("### Interesting Title\nSome **application** content, and [link](https://example.com)\n\n* first\n* second\n---\n"))
+}
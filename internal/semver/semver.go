@@ -0,0 +1,150 @@
+// Package semver parses and orders versions following https://semver.org.
+package semver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidVersion = errors.New("the version is not a valid semantic version")
+
+type Version struct {
+	Major, Minor, Patch uint64
+	// Prerelease identifiers, e.g. ["rc", "1"] for 1.0.0-rc.1.
+	Prerelease []string
+	// Build metadata is kept but it doesn't affect the precedence.
+	Build string
+}
+
+// Parse parses a semantic version, a leading "v" is accepted.
+func Parse(raw string) (Version, error) {
+	raw = strings.TrimPrefix(raw, "v")
+	v := Version{}
+
+	if i := strings.Index(raw, "+"); i >= 0 {
+		v.Build = raw[i+1:]
+		if v.Build == "" {
+			return Version{}, ErrInvalidVersion
+		}
+		raw = raw[:i]
+	}
+	if i := strings.Index(raw, "-"); i >= 0 {
+		prerelease := raw[i+1:]
+		raw = raw[:i]
+		v.Prerelease = strings.Split(prerelease, ".")
+		for _, id := range v.Prerelease {
+			if id == "" || (isNumeric(id) && len(id) > 1 && id[0] == '0') {
+				return Version{}, ErrInvalidVersion
+			}
+		}
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return Version{}, ErrInvalidVersion
+	}
+	numbers := [3]uint64{}
+	for i, part := range parts {
+		// Leading zeroes are not allowed.
+		if !isNumeric(part) || (len(part) > 1 && part[0] == '0') {
+			return Version{}, ErrInvalidVersion
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return Version{}, ErrInvalidVersion
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if v has lower, equal or higher precedence than other.
+func (v Version) Compare(other Version) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// A version without prerelease has higher precedence than one with it.
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+// CompareStrings orders two raw versions. Invalid versions have lower precedence than valid
+// ones and are compared lexically between them so the order is always total.
+func CompareStrings(a, b string) int {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	switch {
+	case errA == nil && errB == nil:
+		if c := va.Compare(vb); c != 0 {
+			return c
+		}
+		// Keep equal precedence versions (e.g. different build metadata) in a stable order.
+		return strings.Compare(a, b)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareIdentifier compares prerelease identifiers, numeric identifiers have lower
+// precedence than alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case aNumeric && bNumeric:
+		if len(a) != len(b) {
+			return compareUint(uint64(len(a)), uint64(len(b)))
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package semver
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("v1.2.3-rc.1+build.5")
+	require.NoError(t, err)
+	require.Equal(t, Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}, Build: "build.5"}, v)
+
+	for _, invalid := range []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.2.x", "1.2.3-", "1.2.3-01", "1.2.3-rc..1", "1.2.3+"} {
+		_, err := Parse(invalid)
+		require.Equal(t, ErrInvalidVersion, err, invalid)
+	}
+}
+
+func TestCompareStrings(t *testing.T) {
+	// Sorted by precedence, taken from the examples of the specification.
+	expected := []string{
+		"latest",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	versions := []string{"1.10.0", "1.0.0-beta.11", "2.0.0", "1.0.0", "1.0.0-alpha.beta", "latest",
+		"1.0.0-rc.1", "1.0.0-alpha", "1.2.0", "1.0.0-beta", "1.0.1", "1.0.0-beta.2", "1.0.0-alpha.1"}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareStrings(versions[i], versions[j]) < 0
+	})
+	require.Equal(t, expected, versions)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+type AnalyzersResponse struct {
+	// Available are the names of the analyzers that can be set.
+	Available []string            `json:"available"`
+	Fields    []api.FieldAnalyzer `json:"fields"`
+}
+
+type SetAnalyzerRequest struct {
+	Analyzer string `json:"analyzer"`
+}
+
+func (h *handler) handleListAnalyzers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &AnalyzersResponse{
+		Available: store.Analyzers(),
+		Fields:    h.Store.FieldAnalyzers(),
+	})
+}
+
+// handleSetAnalyzer changes the analyzer of a full text field, the field is reindexed
+// before responding.
+func (h *handler) handleSetAnalyzer(w http.ResponseWriter, r *http.Request) {
+	var req SetAnalyzerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	analyzer, err := h.Store.SetAnalyzer(api.SearchField(mux.Vars(r)["field"]), req.Analyzer)
+	switch {
+	case errors.Is(err, store.ErrInvalidAnalyzer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, store.ErrNotFullText):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, &analyzer)
+}
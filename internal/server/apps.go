@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+type AppsResponse struct {
+	Apps []api.App `json:"apps"`
+}
+
+type AppVersionsResponse struct {
+	Slug string `json:"slug"`
+	// Versions are sorted from the newest to the oldest.
+	Versions []RecordResponse `json:"versions"`
+}
+
+func (h *handler) handleListApps(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &AppsResponse{Apps: h.Store.Apps()})
+}
+
+func (h *handler) handleAppVersions(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	records, err := h.Store.AppVersions(slug)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrAppNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	res := AppVersionsResponse{Slug: slug, Versions: []RecordResponse{}}
+	for _, record := range records {
+		rawRecord, err := marshalRecord(record)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Versions = append(res.Versions, RecordResponse{ID: record.ID, Revision: record.Revision, Record: rawRecord})
+	}
+	writeJSON(w, http.StatusOK, &res)
+}
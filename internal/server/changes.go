@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+)
+
+// lastChangeHeader carries the sequence number of the last change reflected in a response,
+// the change feed can be resumed from it to keep the response up to date.
+const lastChangeHeader = "X-Last-Change-Seq"
+
+// ChangeEvent is the data of the events sent to the change feed.
+type ChangeEvent struct {
+	Seq       uint64       `json:"seq"`
+	Op        api.ChangeOp `json:"op"`
+	RecordID  string       `json:"recordId"`
+	Revision  int          `json:"revision"`
+	Author    string       `json:"author"`
+	Timestamp time.Time    `json:"timestamp"`
+	// Record is the yaml document of the record after the change, empty for deletes.
+	Record string `json:"record,omitempty"`
+}
+
+func newChangeEvent(change api.Change) (ChangeEvent, error) {
+	event := ChangeEvent{
+		Seq:       change.Seq,
+		Op:        change.Op,
+		RecordID:  change.RecordID,
+		Revision:  change.Revision,
+		Author:    change.Author,
+		Timestamp: change.Timestamp,
+	}
+	if change.Record != nil {
+		rawRecord, err := marshalRecord(change.Record)
+		if err != nil {
+			return event, err
+		}
+		event.Record = rawRecord
+	}
+	return event, nil
+}
+
+// encodeChange encodes a change as the json payload sent to webhooks, which is the same
+// as the data of the change feed events.
+func encodeChange(change api.Change) ([]byte, error) {
+	event, err := newChangeEvent(change)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&event)
+}
+
+// changesSince returns the sequence number the change feed should be resumed from and
+// whether the client provided one. The since query string parameter takes precedence over
+// the Last-Event-ID header.
+func changesSince(r *http.Request) (uint64, bool, error) {
+	raw := r.URL.Query().Get("since")
+	if raw == "" {
+		if r.Header.Get("Last-Event-ID") == "" && r.URL.Query().Get("lastEventId") == "" {
+			return 0, false, nil
+		}
+		since, err := lastEventID(r)
+		return since, true, err
+	}
+	since, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false, errors.New("since must be a non negative integer")
+	}
+	return since, true, nil
+}
+
+// handleChanges streams every mutation of the records as Server-Sent Events.
+// Every event is named after the operation (create, update or delete) and its id is
+// the sequence number of the change.
+func (h *handler) handleChanges(w http.ResponseWriter, r *http.Request) {
+	since, resumed, err := changesSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !resumed {
+		since = h.Store.OldestChange()
+	}
+
+	changes, next, err := h.Store.Changes(since)
+	// New clients start from the oldest change that is still kept, which can be discarded
+	// before they read it.
+	for !resumed && errors.Is(err, store.ErrChangesExpired) {
+		since = h.Store.OldestChange()
+		changes, next, err = h.Store.Changes(since)
+	}
+	if err != nil {
+		// The client has to fetch the whole catalogue again and resume from the last change.
+		if errors.Is(err, store.ErrChangesExpired) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stream, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		for _, change := range changes {
+			event, err := newChangeEvent(change)
+			if err != nil {
+				return
+			}
+			// The client is gone.
+			if err := stream.event(change.Seq, string(change.Op), &event); err != nil {
+				return
+			}
+			since = change.Seq
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := stream.heartbeat(); err != nil {
+				return
+			}
+			changes = nil
+			continue
+		case <-next:
+		}
+
+		// If the client is too slow and the changes expire the stream is closed, the
+		// client will get a 410 when reconnecting.
+		changes, next, err = h.Store.Changes(since)
+		if err != nil {
+			return
+		}
+	}
+}
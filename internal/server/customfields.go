@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+type CustomFieldsResponse struct {
+	Fields []api.CustomField `json:"fields"`
+}
+
+// customFieldErrStatus maps the errors returned by the store custom fields api to status codes.
+func customFieldErrStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrCustomFieldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrCustomFieldExists), errors.Is(err, store.ErrCustomFieldConflict),
+		errors.Is(err, store.ErrCustomFieldInUse):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrInvalidCustomField):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *handler) handleCreateCustomField(w http.ResponseWriter, r *http.Request) {
+	var req api.CustomField
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	field, err := h.Store.RegisterCustomField(req)
+	if err != nil {
+		http.Error(w, err.Error(), customFieldErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, &field)
+}
+
+func (h *handler) handleListCustomFields(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &CustomFieldsResponse{Fields: h.Store.CustomFields()})
+}
+
+func (h *handler) handleGetCustomField(w http.ResponseWriter, r *http.Request) {
+	field, err := h.Store.CustomField(mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), customFieldErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &field)
+}
+
+func (h *handler) handleDeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	if err := h.Store.DeleteCustomField(mux.Vars(r)["name"]); err != nil {
+		http.Error(w, err.Error(), customFieldErrStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultValuesLimit = 50
+	maxValuesLimit     = 1000
+
+	// Sort options for the field values endpoint.
+	valuesSortByValue = "value"
+	valuesSortByCount = "count"
+
+	// Order options for the field values endpoint.
+	valuesOrderAsc  = "asc"
+	valuesOrderDesc = "desc"
+)
+
+type FieldValuesResponse struct {
+	Field  api.SearchField  `json:"field"`
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+	Limit  int              `json:"limit"`
+	Values []api.FieldValue `json:"values"`
+}
+
+// valuesListOptions holds the parsed query string parameters of a field values request.
+type valuesListOptions struct {
+	sort   string
+	order  string
+	offset int
+	limit  int
+}
+
+func parseValuesListOptions(r *http.Request) (valuesListOptions, error) {
+	q := r.URL.Query()
+	opts := valuesListOptions{
+		sort:   valuesSortByValue,
+		order:  valuesOrderAsc,
+		offset: 0,
+		limit:  defaultValuesLimit,
+	}
+
+	if s := q.Get("sort"); s != "" {
+		if s != valuesSortByValue && s != valuesSortByCount {
+			return opts, errors.New("sort must be one of: value,count")
+		}
+		opts.sort = s
+		// Sorting by count is mostly useful to find the most common values first.
+		if s == valuesSortByCount {
+			opts.order = valuesOrderDesc
+		}
+	}
+
+	if o := q.Get("order"); o != "" {
+		if o != valuesOrderAsc && o != valuesOrderDesc {
+			return opts, errors.New("order must be one of: asc,desc")
+		}
+		opts.order = o
+	}
+
+	if o := q.Get("offset"); o != "" {
+		offset, err := strconv.Atoi(o)
+		if err != nil || offset < 0 {
+			return opts, errors.New("offset must be a non negative integer")
+		}
+		opts.offset = offset
+	}
+
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 || limit > maxValuesLimit {
+			return opts, errors.New("limit must be an integer between 1 and 1000")
+		}
+		opts.limit = limit
+	}
+
+	return opts, nil
+}
+
+func (h *handler) handleFieldValues(w http.ResponseWriter, r *http.Request) {
+	field := api.SearchField(mux.Vars(r)["field"])
+	if err := field.IsValid(); err != nil && !h.Store.IsSearchable(field) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseValuesListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := h.Store.FieldValues(field)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The store returns the values sorted by value in ascending order, the stable sort
+	// keeps that order as the tie breaker when sorting by count.
+	sort.SliceStable(values, func(i, j int) bool {
+		if opts.sort == valuesSortByCount {
+			if opts.order == valuesOrderDesc {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Count < values[j].Count
+		}
+		if opts.order == valuesOrderDesc {
+			return values[i].Value > values[j].Value
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	res := FieldValuesResponse{
+		Field:  field,
+		Total:  len(values),
+		Offset: opts.offset,
+		Limit:  opts.limit,
+		Values: []api.FieldValue{},
+	}
+	if opts.offset < len(values) {
+		end := opts.offset + opts.limit
+		if end > len(values) {
+			end = len(values)
+		}
+		res.Values = values[opts.offset:end]
+	}
+
+	writeJSON(w, http.StatusOK, &res)
+}
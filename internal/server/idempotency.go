@@ -0,0 +1,179 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader identifies a request so retries of it are only processed once.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses that were stored by a previous request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotentResponse is the outcome of a request made with an idempotency key.
+type idempotentResponse struct {
+	// fingerprint is the hash of the method, query and body of the request, retries must
+	// send the same ones.
+	fingerprint [sha256.Size]byte
+	// done is false while the first request is being processed.
+	done      bool
+	status    int
+	header    http.Header
+	body      []byte
+	expiresAt time.Time
+}
+
+// expiringKey is a stored response in the order they expire.
+type expiringKey struct {
+	key   string
+	entry *idempotentResponse
+}
+
+// idempotencyCache stores the responses of requests with an idempotency key so retries
+// get the original response instead of repeating the operation.
+type idempotencyCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*idempotentResponse
+	// expiring holds the stored responses from the first to expire to the last one. Every
+	// response is kept for the same ttl so they expire in the order they were stored.
+	expiring []expiringKey
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{ttl: ttl, entries: map[string]*idempotentResponse{}}
+}
+
+// responseRecorder captures a response so it can be stored and replayed.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(data)
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func writeStoredResponse(w http.ResponseWriter, status int, header http.Header, body []byte) {
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// idempotent wraps a handler so requests with the same idempotency key are processed once.
+// Keys are scoped to the path of the request. A retry with a different method, query or body
+// is rejected with 422 and a retry that arrives while the original is still being processed with 409.
+// Server errors are not stored so the request can be retried.
+func (c *idempotencyCache) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "the idempotency key can't be longer than 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		// The query is part of the fingerprint because it can change the operation, e.g. upserts.
+		fingerprint := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RawQuery+"\n"), body...))
+		key = r.URL.Path + " " + key
+
+		c.mu.Lock()
+		c.evictExpired()
+		if stored, ok := c.entries[key]; ok {
+			c.mu.Unlock()
+			switch {
+			case stored.fingerprint != fingerprint:
+				http.Error(w, "the idempotency key was already used with a different request", http.StatusUnprocessableEntity)
+			case !stored.done:
+				http.Error(w, "a request with the same idempotency key is being processed", http.StatusConflict)
+			default:
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				writeStoredResponse(w, stored.status, stored.header, stored.body)
+			}
+			return
+		}
+		entry := &idempotentResponse{fingerprint: fingerprint}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		rec := &responseRecorder{header: http.Header{}}
+		completed := false
+		// If the handler panics the key is released so the request can be retried.
+		defer func() {
+			if !completed {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+		}()
+		next(rec, r)
+		completed = true
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		c.mu.Lock()
+		if rec.status >= http.StatusInternalServerError {
+			delete(c.entries, key)
+		} else {
+			entry.done = true
+			entry.status = rec.status
+			entry.header = rec.header
+			entry.body = rec.body.Bytes()
+			entry.expiresAt = time.Now().Add(c.ttl)
+			c.expiring = append(c.expiring, expiringKey{key: key, entry: entry})
+		}
+		c.mu.Unlock()
+
+		writeStoredResponse(w, rec.status, rec.header, rec.body.Bytes())
+	}
+}
+
+// evictExpired removes the stored responses older than the ttl, the caller must hold the lock.
+// Only the expired responses are visited.
+func (c *idempotencyCache) evictExpired() {
+	now := time.Now()
+	for len(c.expiring) > 0 && now.After(c.expiring[0].entry.expiresAt) {
+		expiring := c.expiring[0]
+		// Keys are only stored again once evicted, this is just a safeguard.
+		if c.entries[expiring.key] == expiring.entry {
+			delete(c.entries, expiring.key)
+		}
+		// Clear the slot so the response can be garbage collected before the array is.
+		c.expiring[0] = expiringKey{}
+		c.expiring = c.expiring[1:]
+	}
+}
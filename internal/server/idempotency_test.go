@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentPanic(t *testing.T) {
+	c := newIdempotencyCache(time.Minute)
+	calls := 0
+	h := c.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("handler failure")
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	request := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/records", nil)
+		r.Header.Set(IdempotencyKeyHeader, "key")
+		return r
+	}
+
+	require.Panics(t, func() { h(httptest.NewRecorder(), request()) })
+	res := httptest.NewRecorder()
+	h(res, request())
+	require.Equal(t, http.StatusCreated, res.Code, "the key should be released when the handler panics")
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyEviction(t *testing.T) {
+	c := newIdempotencyCache(time.Hour)
+	h := c.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	request := func(key string) {
+		r := httptest.NewRequest(http.MethodPost, "/records", nil)
+		r.Header.Set(IdempotencyKeyHeader, key)
+		h(httptest.NewRecorder(), r)
+	}
+
+	request("first")
+	request("second")
+	require.Len(t, c.expiring, 2)
+	c.entries["/records first"].expiresAt = time.Now().Add(-time.Second)
+
+	request("third")
+	require.Len(t, c.entries, 2, "the expired response should be evicted")
+	require.NotContains(t, c.entries, "/records first")
+	require.Len(t, c.expiring, 2)
+	require.Equal(t, "/records second", c.expiring[0].key)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+type MaintainersResponse struct {
+	Maintainers []api.MaintainerProfile `json:"maintainers"`
+}
+
+type MaintainerResponse struct {
+	api.MaintainerProfile
+	// Records are sorted by id, like the record ids of the profile.
+	Records []RecordResponse `json:"records"`
+}
+
+func (h *handler) handleListMaintainers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &MaintainersResponse{Maintainers: h.Store.Maintainers()})
+}
+
+func (h *handler) handleGetMaintainer(w http.ResponseWriter, r *http.Request) {
+	profile, records, err := h.Store.Maintainer(mux.Vars(r)["email"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrMaintainerNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	res := MaintainerResponse{MaintainerProfile: profile, Records: []RecordResponse{}}
+	for _, record := range records {
+		rawRecord, err := marshalRecord(record)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Records = append(res.Records, RecordResponse{ID: record.ID, Revision: record.Revision, Record: rawRecord})
+	}
+	writeJSON(w, http.StatusOK, &res)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/AYM1607/goAKSChallenge/internal/webhook"
+)
+
+// Option configures the server.
+type Option func(*options)
+
+type options struct {
+	webhooks webhook.Config
+	store    []store.Option
+	// idempotencyTTL is how long the responses of requests with an idempotency key are kept.
+	idempotencyTTL time.Duration
+	// ctx bounds the background work of the server.
+	ctx context.Context
+}
+
+func defaultOptions() options {
+	return options{
+		webhooks:       webhook.DefaultConfig(),
+		idempotencyTTL: 24 * time.Hour,
+		ctx:            context.Background(),
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithContext sets the context the background work of the server, like the webhook
+// deliveries, runs with. It stops when the context is done.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithWebhookConfig sets the configuration of the webhook deliveries.
+func WithWebhookConfig(cfg webhook.Config) Option {
+	return func(o *options) {
+		o.webhooks = cfg
+	}
+}
+
+// WithStoreOptions sets the options the record store is created with.
+func WithStoreOptions(opts ...store.Option) Option {
+	return func(o *options) {
+		o.store = append(o.store, opts...)
+	}
+}
+
+// WithIdempotencyTTL sets how long retries of a request with an idempotency key get the
+// original response.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.idempotencyTTL = ttl
+	}
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/markdown"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// AuthorHeader identifies who performed a mutation, it is recorded in the history of the record.
+	AuthorHeader  = "X-Author"
+	defaultAuthor = "anonymous"
+)
+
+type UpdateRequest = CreateRequest
+
+type RecordResponse struct {
+	ID       string `json:"id"`
+	Revision int    `json:"revision"`
+	Record   string `json:"record"`
+}
+
+type HistoryResponse struct {
+	ID        string         `json:"id"`
+	Revisions []api.Revision `json:"revisions"`
+}
+
+type DiffResponse struct {
+	ID      string          `json:"id"`
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Changes []api.FieldDiff `json:"changes"`
+}
+
+func recordErrStatus(err error) int {
+	if errors.Is(err, store.ErrRecordNotFound) || errors.Is(err, store.ErrRevisionNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, store.ErrPreconditionFailed) {
+		return http.StatusPreconditionFailed
+	}
+	if errors.Is(err, store.ErrRecordExists) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, store.ErrLicenseDenied) {
+		return http.StatusUnprocessableEntity
+	}
+	// Any other error is caused by the contents of the payload.
+	return http.StatusBadRequest
+}
+
+// writeOptions returns the metadata of a mutation request.
+func writeOptions(r *http.Request) store.WriteOptions {
+	author := r.Header.Get(AuthorHeader)
+	if author == "" {
+		author = defaultAuthor
+	}
+	return store.WriteOptions{Author: author, IfMatch: ifMatch(r.Header.Get("If-Match"))}
+}
+
+// etag returns the entity tag of a revision of a record.
+// Revisions are only ever incremented, so the revision number identifies the contents.
+func etag(revision int) string {
+	return fmt.Sprintf(`"%d"`, revision)
+}
+
+// ifMatch returns a function that checks the current revision of a record against the
+// entity tags of an If-Match header, nil if the header is not present or is "*".
+// Weak entity tags never match since If-Match requires a strong comparison.
+func ifMatch(header string) func(revision int) bool {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil
+	}
+	tags := map[string]bool{}
+	for _, tag := range strings.Split(header, ",") {
+		tags[strings.TrimSpace(tag)] = true
+	}
+	return func(revision int) bool {
+		return tags[etag(revision)]
+	}
+}
+
+func writeRecord(w http.ResponseWriter, status int, record *api.MetaRecord) {
+	rawRecord, err := marshalRecord(record)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", etag(record.Revision))
+	writeJSON(w, status, &RecordResponse{ID: record.ID, Revision: record.Revision, Record: rawRecord})
+}
+
+// handleGetRecord returns the current version of a record.
+// A past version can be requested either by its number with the revision query string
+// parameter or with a RFC3339 timestamp with the at query string parameter.
+func (h *handler) handleGetRecord(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	q := r.URL.Query()
+
+	if q.Get("revision") == "" && q.Get("at") == "" {
+		record, err := h.Store.Get(id)
+		if err != nil {
+			http.Error(w, err.Error(), recordErrStatus(err))
+			return
+		}
+		writeRecord(w, http.StatusOK, record)
+		return
+	}
+
+	var revision api.Revision
+	var err error
+	if raw := q.Get("revision"); raw != "" {
+		number, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			http.Error(w, "revision must be an integer", http.StatusBadRequest)
+			return
+		}
+		revision, err = h.Store.Revision(id, number)
+	} else {
+		at, parseErr := time.Parse(time.RFC3339, q.Get("at"))
+		if parseErr != nil {
+			http.Error(w, "at must be a RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		revision, err = h.Store.RevisionAt(id, at)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+
+	if revision.Record == nil {
+		http.Error(w, fmt.Sprintf("the record was deleted at revision %d", revision.Revision), http.StatusNotFound)
+		return
+	}
+	writeRecord(w, http.StatusOK, revision.Record)
+}
+
+func (h *handler) handleUpdateRecord(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.Store.Update(mux.Vars(r)["id"], []byte(req.Record), writeOptions(r))
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	writeRecord(w, http.StatusOK, record)
+}
+
+func (h *handler) handleDeleteRecord(w http.ResponseWriter, r *http.Request) {
+	err := h.Store.Delete(mux.Vars(r)["id"], writeOptions(r))
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) handleRecordHistory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	revisions, err := h.Store.History(id)
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &HistoryResponse{ID: id, Revisions: revisions})
+}
+
+// handleRecordDiff compares the revisions provided in the from and to query string parameters.
+func (h *handler) handleRecordDiff(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "from must be an integer", http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "to must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.Store.Diff(id, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &DiffResponse{ID: id, From: from, To: to, Changes: changes})
+}
+
+func (h *handler) handleRecordCompliance(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Store.Compliance(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &report)
+}
+
+// handleRecordDescription renders the markdown description of a record as HTML.
+func (h *handler) handleRecordDescription(w http.ResponseWriter, r *http.Request) {
+	record, err := h.Store.Get(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// The renderer never produces scripts, styles or embedded content. The policy is a
+	// second line of defense for clients that display the document directly.
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", etag(record.Revision))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, markdown.ToHTML(record.Description))
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+)
+
+type SchemaVersionsResponse struct {
+	Current string `json:"current"`
+	// Versions are sorted from the oldest to the current one.
+	Versions []string `json:"versions"`
+	// Schemas has the path of the JSON schema of every version.
+	Schemas map[string]string `json:"schemas"`
+}
+
+// handleSchema returns the JSON schema of the records of the version in the version query
+// string parameter, the current one by default. It includes the custom fields.
+func (h *handler) handleSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := h.Store.JSONSchema(r.URL.Query().Get("version"))
+	if err != nil {
+		if errors.Is(err, store.ErrUnsupportedAPIVersion) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+// handleSchemaVersions lists the apiVersion values accepted by the server. Records of
+// previous versions are upgraded to the current one when they are added.
+func (h *handler) handleSchemaVersions(w http.ResponseWriter, r *http.Request) {
+	res := SchemaVersionsResponse{Current: api.APIVersionV1, Versions: store.SchemaVersions(), Schemas: map[string]string{}}
+	for _, version := range res.Versions {
+		res.Schemas[version] = "/schema?version=" + url.QueryEscape(version)
+	}
+	writeJSON(w, http.StatusOK, &res)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+type CreateSavedSearchRequest struct {
+	Name    string        `json:"name"`
+	Request SearchRequest `json:"request"`
+}
+
+type UpdateSavedSearchRequest struct {
+	Request SearchRequest `json:"request"`
+}
+
+type SavedSearchesResponse struct {
+	Searches []api.SavedSearch `json:"searches"`
+}
+
+// savedSearchErrStatus maps the errors returned by the store saved searches api to status codes.
+func savedSearchErrStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrSavedSearchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrSavedSearchExists):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrInvalidSearchName):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *handler) handleCreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var req CreateSavedSearchRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Searches are validated when saved so executing them only fails if the data changes.
+	if err := h.validateSearchRequest(req.Request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.Store.CreateSavedSearch(req.Name, req.Request)
+	if err != nil {
+		http.Error(w, err.Error(), savedSearchErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, &search)
+}
+
+func (h *handler) handleListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &SavedSearchesResponse{Searches: h.Store.SavedSearches()})
+}
+
+func (h *handler) handleGetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	search, err := h.Store.SavedSearch(mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), savedSearchErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &search)
+}
+
+func (h *handler) handleUpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var req UpdateSavedSearchRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validateSearchRequest(req.Request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.Store.UpdateSavedSearch(mux.Vars(r)["name"], req.Request)
+	if err != nil {
+		http.Error(w, err.Error(), savedSearchErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &search)
+}
+
+func (h *handler) handleDeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	err := h.Store.DeleteSavedSearch(mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), savedSearchErrStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) handleSavedSearchResults(w http.ResponseWriter, r *http.Request) {
+	search, err := h.Store.SavedSearch(mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), savedSearchErrStatus(err))
+		return
+	}
+	h.writeSearchResults(w, search.Request)
+}
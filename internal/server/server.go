@@ -1,53 +1,119 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/AYM1607/goAKSChallenge/api"
 	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/AYM1607/goAKSChallenge/internal/webhook"
 	"github.com/goccy/go-yaml"
 	"github.com/gorilla/mux"
 )
 
 const searchErrString = "search request could not be completed due to an internal error"
 
-func NewServer(addr string) (*http.Server, error) {
-	r, err := NewHTTPHandler()
+// NewServer creates a server whose background work, like the webhook deliveries, is
+// stopped when the server is shut down.
+func NewServer(addr string, opts ...Option) (*http.Server, error) {
+	o := applyOptions(opts)
+	ctx, cancel := context.WithCancel(o.ctx)
+	o.ctx = ctx
+	r, err := newHTTPHandler(o)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return &http.Server{
+	srv := &http.Server{
 		Addr:    addr,
 		Handler: r,
-	}, nil
+	}
+	srv.RegisterOnShutdown(cancel)
+	return srv, nil
 }
 
 type handler struct {
-	Store *store.Store
+	Store    *store.Store
+	Webhooks *webhook.Dispatcher
+}
+
+// NewHTTPHandler creates the handler of the api. Its background work runs until the
+// context set with WithContext is done.
+func NewHTTPHandler(opts ...Option) (http.Handler, error) {
+	return newHTTPHandler(applyOptions(opts))
 }
 
-func NewHTTPHandler() (http.Handler, error) {
-	store, err := store.New()
+func newHTTPHandler(o options) (http.Handler, error) {
+	store, err := store.New(o.store...)
 	if err != nil {
 		return nil, err
 	}
 
+	webhooks := webhook.NewDispatcher(o.webhooks, store, encodeChange)
+	webhooks.Start(o.ctx)
+
 	handler := handler{
-		Store: store,
+		Store:    store,
+		Webhooks: webhooks,
 	}
 
 	r := mux.NewRouter()
 
-	r.HandleFunc("/records", handler.handleCreate).Methods("POST")
+	idempotency := newIdempotencyCache(o.idempotencyTTL)
+	r.HandleFunc("/records", idempotency.idempotent(handler.handleCreate)).Methods("POST")
+	r.HandleFunc("/records/bulk", idempotency.idempotent(handler.handleBulkCreate)).Methods("POST")
 	// We could debate using POST or GET for a search endpoint. For this challenge I'll prioritize ease of parsing.
 	// Since the GET verb does not support a body, we would need to parse search terms from the URL.
 	// If the requirements mentioned compatibility with browsers or ease of query sharing the effort of using
 	// query string params would be justified.
 	r.HandleFunc("/records/search", handler.handleSearch).Methods("POST")
+	r.HandleFunc("/records/{id}", handler.handleGetRecord).Methods("GET")
+	r.HandleFunc("/records/{id}", handler.handleUpdateRecord).Methods("PUT")
+	r.HandleFunc("/records/{id}", handler.handleDeleteRecord).Methods("DELETE")
+	r.HandleFunc("/records/{id}/history", handler.handleRecordHistory).Methods("GET")
+	r.HandleFunc("/records/{id}/diff", handler.handleRecordDiff).Methods("GET")
+	r.HandleFunc("/records/{id}/compliance", handler.handleRecordCompliance).Methods("GET")
+	r.HandleFunc("/records/{id}/description.html", handler.handleRecordDescription).Methods("GET")
+	r.HandleFunc("/changes", handler.handleChanges).Methods("GET")
+
+	r.HandleFunc("/apps", handler.handleListApps).Methods("GET")
+	r.HandleFunc("/apps/{slug}/versions", handler.handleAppVersions).Methods("GET")
+	r.HandleFunc("/maintainers", handler.handleListMaintainers).Methods("GET")
+	r.HandleFunc("/maintainers/{email}", handler.handleGetMaintainer).Methods("GET")
+
+	r.HandleFunc("/webhooks", handler.handleCreateWebhook).Methods("POST")
+	r.HandleFunc("/webhooks", handler.handleListWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks/{id}", handler.handleGetWebhook).Methods("GET")
+	r.HandleFunc("/webhooks/{id}", handler.handleDeleteWebhook).Methods("DELETE")
+	r.HandleFunc("/admin/webhooks/deadletters", handler.handleWebhookDeadLetters).Methods("GET")
+	r.HandleFunc("/fields/{field}/values", handler.handleFieldValues).Methods("GET")
+	r.HandleFunc("/schema", handler.handleSchema).Methods("GET")
+	r.HandleFunc("/schema/versions", handler.handleSchemaVersions).Methods("GET")
+	r.HandleFunc("/admin/fields", handler.handleCreateCustomField).Methods("POST")
+	r.HandleFunc("/admin/fields", handler.handleListCustomFields).Methods("GET")
+	r.HandleFunc("/admin/fields/{name}", handler.handleGetCustomField).Methods("GET")
+	r.HandleFunc("/admin/fields/{name}", handler.handleDeleteCustomField).Methods("DELETE")
+	r.HandleFunc("/admin/analyzers", handler.handleListAnalyzers).Methods("GET")
+	r.HandleFunc("/admin/analyzers/{field}", handler.handleSetAnalyzer).Methods("PUT")
+
+	r.HandleFunc("/searches", handler.handleCreateSavedSearch).Methods("POST")
+	r.HandleFunc("/searches", handler.handleListSavedSearches).Methods("GET")
+	r.HandleFunc("/searches/{name}", handler.handleGetSavedSearch).Methods("GET")
+	r.HandleFunc("/searches/{name}", handler.handleUpdateSavedSearch).Methods("PUT")
+	r.HandleFunc("/searches/{name}", handler.handleDeleteSavedSearch).Methods("DELETE")
+	r.HandleFunc("/searches/{name}/results", handler.handleSavedSearchResults).Methods("GET")
+
+	r.HandleFunc("/subscriptions", handler.handleCreateSubscription).Methods("POST")
+	r.HandleFunc("/subscriptions", handler.handleListSubscriptions).Methods("GET")
+	r.HandleFunc("/subscriptions/{id}", handler.handleGetSubscription).Methods("GET")
+	r.HandleFunc("/subscriptions/{id}", handler.handleDeleteSubscription).Methods("DELETE")
+	r.HandleFunc("/subscriptions/{id}/events", handler.handleSubscriptionEvents).Methods("GET")
 
 	return r, nil
 }
@@ -58,19 +124,42 @@ type CreateRequest struct {
 
 type CreateResponse struct {
 	Message string `json:"message"`
+	ID      string `json:"id"`
 }
 
-type SearchRequest struct {
-	JoinMethod  api.SearchJoinMethod `json:"joinMethod"`
-	SearchTerms []api.SearchTerm     `json:"searchTerms"`
+type BulkCreateRequest struct {
+	Records []string `json:"records"`
+}
+
+// BulkCreateResult is the outcome of one of the records of a bulk create.
+type BulkCreateResult struct {
+	// Status is the status code POST /records would have responded with for the record.
+	Status int    `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkCreateResponse struct {
+	// Results are in the same order as the records of the request.
+	Results []BulkCreateResult `json:"results"`
 }
 
+// SearchRequest is defined in the api package so it can be stored by saved searches.
+type SearchRequest = api.SearchRequest
+
 // Since the yaml is accepted as a string, the records that are found from a search
 // are also returned as strings.
 type SearchResponse struct {
 	Records []string `json:"records"`
+	// IDs holds the id of every record, in the same order as Records.
+	IDs         []string               `json:"ids"`
+	Explanation *api.SearchExplanation `json:"explanation,omitempty"`
+	// Warnings lists the terms that were skipped by a lenient search.
+	Warnings []api.SearchWarning `json:"warnings,omitempty"`
 }
 
+// handleCreate adds a record. Records with the same natural key as an existing one are
+// rejected unless the upsert query string parameter is true, in which case they replace it.
 func (h *handler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	var req CreateRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -80,24 +169,86 @@ func (h *handler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	upsert, err := upsertParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Ensure the payload is valid.
-	err = h.Store.Append([]byte(req.Record))
+	record, replaced, err := h.createRecord([]byte(req.Record), upsert, writeOptions(r))
 	if err != nil {
 		// This error string contains information about what went wrong with the payload processing,
 		// including field names that caused the error.
+		http.Error(w, err.Error(), recordErrStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", etag(record.Revision))
+	if replaced {
+		res := CreateResponse{Message: "The record was replaced successfully.", ID: record.ID}
+		writeJSON(w, http.StatusOK, &res)
+		return
+	}
+	res := CreateResponse{Message: "The record was added successfully.", ID: record.ID}
+	writeJSON(w, http.StatusCreated, &res)
+}
+
+// handleBulkCreate adds many records as handleCreate does with each of them, in order.
+// Records are added independently, the ones that fail don't prevent the rest from being added.
+func (h *handler) handleBulkCreate(w http.ResponseWriter, r *http.Request) {
+	var req BulkCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if len(req.Records) == 0 {
+		http.Error(w, "at least one record is required", http.StatusBadRequest)
+		return
+	}
+	upsert, err := upsertParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := writeOptions(r)
+	res := BulkCreateResponse{Results: []BulkCreateResult{}}
+	for _, rawRecord := range req.Records {
+		record, replaced, err := h.createRecord([]byte(rawRecord), upsert, opts)
+		switch {
+		case err != nil:
+			res.Results = append(res.Results, BulkCreateResult{Status: recordErrStatus(err), Error: err.Error()})
+		case replaced:
+			res.Results = append(res.Results, BulkCreateResult{Status: http.StatusOK, ID: record.ID})
+		default:
+			res.Results = append(res.Results, BulkCreateResult{Status: http.StatusCreated, ID: record.ID})
+		}
+	}
+	writeJSON(w, http.StatusOK, &res)
+}
 
-	res := CreateResponse{Message: "The record was added successfully."}
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(res)
+// upsertParam returns whether the upsert query string parameter of a create request is set.
+func upsertParam(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("upsert")
+	if raw == "" {
+		return false, nil
+	}
+	upsert, err := strconv.ParseBool(raw)
 	if err != nil {
-		// Generic error, since the response is always the same and is always
-		// encodable, this should not happen but leaving it as a safeguard.
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false, errors.New("upsert must be a boolean")
 	}
+	return upsert, nil
+}
 
+// createRecord adds a record, replacing the one with the same natural key if upsert is set.
+// Reports whether an existing record was replaced.
+func (h *handler) createRecord(rawRecord []byte, upsert bool, opts store.WriteOptions) (*api.MetaRecord, bool, error) {
+	if upsert {
+		return h.Store.Upsert(rawRecord, opts)
+	}
+	record, err := h.Store.Append(rawRecord, opts)
+	return record, false, err
 }
 
 func (h *handler) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -110,51 +261,100 @@ func (h *handler) handleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ensure payload is valid.
-	if err := req.JoinMethod.IsValid(); err != nil {
+	if err := h.validateSearchRequest(req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+
+	h.writeSearchResults(w, req)
+}
+
+// validateSearchRequest ensures all the enum values of a search request are valid.
+// Indexed custom fields are valid search fields too.
+func (h *handler) validateSearchRequest(req SearchRequest) error {
+	if err := req.JoinMethod.IsValid(); err != nil {
+		return err
+	}
+	if err := req.Mode.IsValid(); err != nil {
+		return err
+	}
 	// NOTE: This validation is linear in terms of time complexity,
 	// beware of search requests with a high number of terms.
 	invalidFields := []string{}
 	for _, term := range req.SearchTerms {
-		if err := term.Field.IsValid(); err != nil {
+		if err := term.Match.IsValid(); err != nil {
+			return err
+		}
+		if err := term.Field.IsValid(); err != nil && !h.Store.IsSearchable(term.Field) {
 			invalidFields = append(invalidFields, string(term.Field))
 		}
 	}
 
 	if len(invalidFields) > 0 {
-		http.Error(w,
-			fmt.Sprintf("the following field(s) are not supported: %s", strings.Join(invalidFields, ",")),
-			http.StatusBadRequest)
-		return
+		return fmt.Errorf("the following field(s) are not supported: %s", strings.Join(invalidFields, ","))
 	}
+	return nil
+}
 
-	records, err := h.Store.Search(req.JoinMethod, req.SearchTerms)
+// writeSearchResults runs a validated search request against the store and writes the response.
+func (h *handler) writeSearchResults(w http.ResponseWriter, req SearchRequest) {
+	// The sequence number is read first so resuming the change feed from it can repeat
+	// changes the results already reflect but never miss one.
+	lastChange := h.Store.LastChange()
+	result, err := h.Store.Search(req.JoinMethod, req.SearchTerms, store.OptionsFromRequest(req))
 	if err != nil {
+		// Terms that can't be resolved are caused by the contents of the request.
+		var termErr *store.TermError
+		if errors.As(err, &termErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	rawRecords := []string{}
-	for _, record := range records {
-
-		// If this option is not used, the indentation of sequences does not match
-		// the original file and thus tests fail.
-		rawRecord, err := yaml.MarshalWithOptions(record, yaml.IndentSequence(true))
+	ids := []string{}
+	for _, record := range result.Records {
+		rawRecord, err := marshalRecord(record)
 		// Since all records where unmarshalled from valid yaml this should not
 		// happen but leaving it as a safeguard.
 		if err != nil {
 			http.Error(w, searchErrString, http.StatusInternalServerError)
 			return
 		}
-		rawRecords = append(rawRecords, string(rawRecord))
+		rawRecords = append(rawRecords, rawRecord)
+		ids = append(ids, record.ID)
 	}
 
-	res := SearchResponse{Records: rawRecords}
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(&res)
+	res := SearchResponse{
+		Records:     rawRecords,
+		IDs:         ids,
+		Explanation: result.Explanation,
+		Warnings:    result.Warnings,
+	}
+	w.Header().Set(lastChangeHeader, strconv.FormatUint(lastChange, 10))
+	writeJSON(w, http.StatusOK, &res)
+}
+
+// marshalRecord encodes a record back to the yaml string format it was received in.
+func marshalRecord(record *api.MetaRecord) (string, error) {
+	// If this option is not used, the indentation of sequences does not match
+	// the original file and thus tests fail.
+	rawRecord, err := yaml.MarshalWithOptions(record, yaml.IndentSequence(true))
 	if err != nil {
-		http.Error(w, searchErrString, http.StatusInternalServerError)
+		return "", err
+	}
+	return string(rawRecord), nil
+}
+
+// writeJSON encodes v as the json body of the response with the provided status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		// The headers were already written so the best we can do is append the error.
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
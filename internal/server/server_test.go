@@ -1,16 +1,23 @@
 package server_test
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/AYM1607/goAKSChallenge/api"
 	"github.com/AYM1607/goAKSChallenge/internal/common"
 	"github.com/AYM1607/goAKSChallenge/internal/server"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/AYM1607/goAKSChallenge/internal/webhook"
 	"github.com/gavv/httpexpect/v2"
 	"github.com/stretchr/testify/require"
 )
@@ -30,14 +37,17 @@ const (
 
 var recordsFps = []string{record1Fp, record2Fp, record3Fp, record4Fp}
 
-func createServer(t *testing.T) *httptest.Server {
-	h, err := server.NewHTTPHandler()
+func createServer(t *testing.T, opts ...server.Option) *httptest.Server {
+	// Stop the background work of the handler when the test ends.
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := server.NewHTTPHandler(append(opts, server.WithContext(ctx))...)
 	require.NoError(t, err, "test server should be able to be created correclty")
 
 	server := httptest.NewServer(h)
 	// Avoid deferring server cleanup in main test function.
 	t.Cleanup(func() {
 		server.Close()
+		cancel()
 	})
 	return server
 }
@@ -178,3 +188,1054 @@ func TestValidCreatesAndSearches(t *testing.T) {
 		})
 	}
 }
+
+// createRecords posts every valid testdata record to the server.
+func createRecords(t *testing.T, e *httpexpect.Expect) {
+	for _, rFp := range recordsFps {
+		rb, err := os.ReadFile(rFp)
+		require.NoError(t, err, "testdata file should be able to be opened successfully.")
+		e.POST("/records").WithJSON(map[string]string{"record": string(rb)}).
+			Expect().
+			Status(http.StatusCreated)
+	}
+}
+
+func TestFieldValues(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	e.GET("/fields/unknown/values").Expect().Status(http.StatusBadRequest)
+	e.GET("/fields/title/values").WithQuery("limit", 0).Expect().Status(http.StatusBadRequest)
+	e.GET("/fields/title/values").WithQuery("sort", "date").Expect().Status(http.StatusBadRequest)
+
+	res := e.GET("/fields/title/values").
+		WithQuery("order", "desc").
+		WithQuery("offset", 1).
+		WithQuery("limit", 2).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	res.ValueEqual("total", 4)
+	res.ValueEqual("values", []api.FieldValue{
+		{Value: "Valid App 3", Count: 1},
+		{Value: "Valid App 2", Count: 1},
+	})
+
+	res = e.GET("/fields/company/values").WithQuery("sort", "count").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+	res.ValueEqual("values", []api.FieldValue{{Value: "Upbound Inc.", Count: 4}})
+}
+
+func TestSearchExplain(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	req := server.SearchRequest{JoinMethod: "and", Explain: true, SearchTerms: []api.SearchTerm{
+		{Field: "website", Query: "https://website1.io"},
+		{Field: "description", Query: "threeForTesting"},
+	}}
+	rawBody := e.POST("/records/search").WithJSON(req).
+		Expect().
+		Status(http.StatusOK).
+		Body().Raw()
+
+	res := server.SearchResponse{}
+	err := json.Unmarshal([]byte(rawBody), &res)
+	require.NoError(t, err, "successfull search requests should be unmarshable")
+	require.Empty(t, res.Records)
+	require.NotNil(t, res.Explanation, "the explanation should be returned when requested")
+	require.Len(t, res.Explanation.Terms, 2)
+	require.Equal(t, 2, res.Explanation.Terms[0].Remaining)
+	require.Equal(t, "fullText", res.Explanation.Terms[1].Index)
+	require.Equal(t, "threefortesting", res.Explanation.Terms[1].NormalizedQuery)
+	require.Equal(t, 1, res.Explanation.Terms[1].Hits)
+	require.Equal(t, 0, res.Explanation.Terms[1].Remaining, "the second term should eliminate every record")
+}
+
+func TestSearchModes(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	terms := []api.SearchTerm{
+		{Field: "maintainerEmail", Query: "man2@mail.com"},
+		{Field: "title", Query: ""},
+	}
+
+	e.POST("/records/search").
+		WithJSON(server.SearchRequest{JoinMethod: "and", Mode: "sloppy", SearchTerms: terms}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	e.POST("/records/search").
+		WithJSON(server.SearchRequest{JoinMethod: "and", Mode: "strict", SearchTerms: terms}).
+		Expect().
+		Status(http.StatusBadRequest).
+		Body().Contains("search term 1")
+
+	rawBody := e.POST("/records/search").
+		WithJSON(server.SearchRequest{JoinMethod: "and", Mode: "lenient", SearchTerms: terms}).
+		Expect().
+		Status(http.StatusOK).
+		Body().Raw()
+
+	res := server.SearchResponse{}
+	err := json.Unmarshal([]byte(rawBody), &res)
+	require.NoError(t, err, "successfull search requests should be unmarshable")
+	require.Len(t, res.Records, 2, "the skipped term should not change the semantics of the AND join")
+	require.Len(t, res.Warnings, 1)
+	require.Equal(t, 1, res.Warnings[0].Term)
+}
+
+func TestSavedSearches(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	req := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "maintainerEmail", Query: "man1@mail.com"},
+	}}
+
+	e.POST("/searches").
+		WithJSON(server.CreateSavedSearchRequest{Name: "bad", Request: server.SearchRequest{JoinMethod: "xor"}}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	e.POST("/searches").WithJSON(server.CreateSavedSearchRequest{Name: "man1", Request: req}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().ValueEqual("name", "man1")
+	e.POST("/searches").WithJSON(server.CreateSavedSearchRequest{Name: "man1", Request: req}).
+		Expect().
+		Status(http.StatusConflict)
+
+	e.GET("/searches").Expect().Status(http.StatusOK).
+		JSON().Object().Value("searches").Array().Length().Equal(1)
+	e.GET("/searches/man1/results").Expect().Status(http.StatusOK).
+		JSON().Object().Value("records").Array().Length().Equal(2)
+
+	req.SearchTerms = append(req.SearchTerms, api.SearchTerm{Field: "title", Query: "Valid App 1"})
+	e.PUT("/searches/man1").WithJSON(server.UpdateSavedSearchRequest{Request: req}).
+		Expect().
+		Status(http.StatusOK)
+	e.GET("/searches/man1/results").Expect().Status(http.StatusOK).
+		JSON().Object().Value("records").Array().Length().Equal(1)
+
+	e.DELETE("/searches/man1").Expect().Status(http.StatusNoContent)
+	e.GET("/searches/man1").Expect().Status(http.StatusNotFound)
+	e.GET("/searches/man1/results").Expect().Status(http.StatusNotFound)
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// openEventStream opens a Server-Sent Events stream that is closed when the test ends.
+func openEventStream(t *testing.T, url string, lastEventID string) *bufio.Reader {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	t.Cleanup(func() {
+		cancel()
+		res.Body.Close()
+	})
+	return bufio.NewReader(res.Body)
+}
+
+// readEvent reads the next event from a Server-Sent Events stream skipping comments.
+func readEvent(t *testing.T, stream *bufio.Reader) sseEvent {
+	event := sseEvent{}
+	for {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err, "the stream should deliver an event")
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.id != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	records := map[string]string{}
+	for _, rFp := range recordsFps {
+		rb, err := os.ReadFile(rFp)
+		require.NoError(t, err, "testdata file should be able to be opened successfully.")
+		records[rFp] = string(rb)
+	}
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	e.POST("/subscriptions").
+		WithJSON(server.CreateSubscriptionRequest{Request: server.SearchRequest{JoinMethod: "nand"}}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.GET("/subscriptions/unknown/events").Expect().Status(http.StatusNotFound)
+
+	id := e.POST("/subscriptions").
+		WithJSON(server.CreateSubscriptionRequest{Request: server.SearchRequest{
+			JoinMethod:  "or",
+			SearchTerms: []api.SearchTerm{{Field: "maintainerEmail", Query: "man2@mail.com"}},
+		}}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+
+	eventsURL := fmt.Sprintf("%s/subscriptions/%s/events", testServer.URL, id)
+	stream := openEventStream(t, eventsURL, "")
+
+	// Only records 3 and 4 are maintained by man2.
+	for _, rFp := range []string{record1Fp, record3Fp, record2Fp, record4Fp} {
+		e.POST("/records").WithJSON(map[string]string{"record": records[rFp]}).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	for i, rFp := range []string{record3Fp, record4Fp} {
+		event := readEvent(t, stream)
+		require.Equal(t, fmt.Sprint(i+1), event.id)
+		require.Equal(t, "match", event.event)
+
+		match := server.SubscriptionMatch{}
+		require.NoError(t, json.Unmarshal([]byte(event.data), &match))
+		require.Equal(t, id, match.SubscriptionID)
+		require.Equal(t, records[rFp], match.Record)
+	}
+
+	// Reconnecting should only replay the events after the last one received.
+	stream = openEventStream(t, eventsURL, "1")
+	event := readEvent(t, stream)
+	require.Equal(t, "2", event.id)
+
+	e.DELETE(fmt.Sprintf("/subscriptions/%s", id)).Expect().Status(http.StatusNoContent)
+	e.GET(fmt.Sprintf("/subscriptions/%s", id)).Expect().Status(http.StatusNotFound)
+}
+
+func TestRecords(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().NotEmpty().Raw()
+	recordPath := fmt.Sprintf("/records/%s", id)
+
+	e.GET(recordPath).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("id", id).ValueEqual("record", string(record1))
+	e.GET("/records/unknown").Expect().Status(http.StatusNotFound)
+
+	e.PUT(recordPath).WithJSON(server.UpdateRequest{Record: "title: [invalid"}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.PUT(recordPath).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ValueEqual("record", string(record2))
+
+	search := server.SearchRequest{JoinMethod: "or", SearchTerms: []api.SearchTerm{
+		{Field: "title", Query: "Valid App 1"},
+		{Field: "title", Query: "Valid App 2"},
+	}}
+	res := e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).JSON().Object()
+	res.ValueEqual("records", []string{string(record2)})
+	res.ValueEqual("ids", []string{id})
+
+	e.DELETE(recordPath).Expect().Status(http.StatusNoContent)
+	e.DELETE(recordPath).Expect().Status(http.StatusNotFound)
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("records").Array().Empty()
+}
+
+func TestRecordETags(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	res := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated)
+	res.Header("ETag").Equal(`"1"`)
+	recordPath := fmt.Sprintf("/records/%s", res.JSON().Object().Value("id").String().Raw())
+
+	e.GET(recordPath).Expect().Status(http.StatusOK).Header("ETag").Equal(`"1"`)
+
+	e.PUT(recordPath).WithHeader("If-Match", `"2"`).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusPreconditionFailed)
+	e.PUT(recordPath).WithHeader("If-Match", `W/"1"`).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusPreconditionFailed)
+	e.PUT(recordPath).WithHeader("If-Match", `"3", "1"`).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusOK).
+		Header("ETag").Equal(`"2"`)
+	e.GET(recordPath).WithQuery("revision", 1).Expect().Status(http.StatusOK).Header("ETag").Equal(`"1"`)
+
+	e.DELETE(recordPath).WithHeader("If-Match", `"1"`).Expect().Status(http.StatusPreconditionFailed)
+	e.DELETE(recordPath).WithHeader("If-Match", "*").Expect().Status(http.StatusNoContent)
+}
+
+func TestNaturalKey(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+
+	e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusConflict).
+		Body().Contains(id)
+	e.POST("/records").WithQuery("upsert", "maybe").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	res := e.POST("/records").WithQuery("upsert", true).WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusOK)
+	res.Header("ETag").Equal(`"2"`)
+	res.JSON().Object().ValueEqual("id", id)
+
+	e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "title", Query: "Valid App 1"},
+	}}).Expect().Status(http.StatusOK).JSON().Object().ValueEqual("ids", []string{id})
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	ttl := 100 * time.Millisecond
+	testServer := createServer(t, server.WithIdempotencyTTL(ttl))
+	e := httpexpect.New(t, testServer.URL)
+
+	res := e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key1").
+		WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated)
+	res.Header(server.IdempotentReplayedHeader).Empty()
+	id := res.JSON().Object().Value("id").String().Raw()
+
+	// The retry gets the original response instead of a conflict.
+	res = e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key1").
+		WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated)
+	res.Header(server.IdempotentReplayedHeader).Equal("true")
+	res.Header("ETag").Equal(`"1"`)
+	res.JSON().Object().ValueEqual("id", id)
+
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key1").
+		WithJSON(server.CreateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusUnprocessableEntity)
+	// The query can change the operation so it must match too.
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key1").WithQuery("upsert", "true").
+		WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusUnprocessableEntity)
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, strings.Repeat("k", 256)).
+		WithJSON(server.CreateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	// Failed requests are stored too.
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key2").
+		WithJSON(server.CreateRequest{Record: "title: [invalid"}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key2").
+		WithJSON(server.CreateRequest{Record: "title: [invalid"}).
+		Expect().
+		Status(http.StatusBadRequest).
+		Header(server.IdempotentReplayedHeader).Equal("true")
+
+	// Once the key expires the request is processed again.
+	time.Sleep(2 * ttl)
+	e.POST("/records").WithHeader(server.IdempotencyKeyHeader, "key1").
+		WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusConflict)
+}
+
+func TestBulkCreate(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	e.POST("/records/bulk").WithJSON(server.BulkCreateRequest{}).Expect().Status(http.StatusBadRequest)
+
+	req := server.BulkCreateRequest{Records: []string{string(record1), "title: [invalid", string(record1)}}
+	rawBody := e.POST("/records/bulk").WithHeader(server.IdempotencyKeyHeader, "bulk").WithJSON(req).
+		Expect().
+		Status(http.StatusOK).
+		Body().Raw()
+	res := server.BulkCreateResponse{}
+	require.NoError(t, json.Unmarshal([]byte(rawBody), &res))
+	require.Len(t, res.Results, 3)
+	require.Equal(t, http.StatusCreated, res.Results[0].Status)
+	require.NotEmpty(t, res.Results[0].ID)
+	require.Equal(t, http.StatusBadRequest, res.Results[1].Status)
+	require.NotEmpty(t, res.Results[1].Error)
+	require.Equal(t, http.StatusConflict, res.Results[2].Status, "records are added one after the other")
+
+	// Retries get the original response instead of adding the records again.
+	e.POST("/records/bulk").WithHeader(server.IdempotencyKeyHeader, "bulk").WithJSON(req).
+		Expect().
+		Status(http.StatusOK).
+		Header(server.IdempotentReplayedHeader).Equal("true")
+
+	req = server.BulkCreateRequest{Records: []string{string(record1), string(record2)}}
+	rawBody = e.POST("/records/bulk").WithQuery("upsert", "true").WithJSON(req).
+		Expect().
+		Status(http.StatusOK).
+		Body().Raw()
+	res = server.BulkCreateResponse{}
+	require.NoError(t, json.Unmarshal([]byte(rawBody), &res))
+	require.Equal(t, []server.BulkCreateResult{
+		{Status: http.StatusOK, ID: res.Results[0].ID},
+		{Status: http.StatusCreated, ID: res.Results[1].ID},
+	}, res.Results)
+}
+
+func TestApps(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	for _, version := range []string{"1.0.10", "1.0.2-rc.1", "1.0.2"} {
+		e.POST("/records").WithJSON(server.CreateRequest{
+			Record: strings.Replace(string(record1), "version: 1.0.1", "version: "+version, 1),
+		}).Expect().Status(http.StatusCreated)
+	}
+
+	apps := e.GET("/apps").Expect().Status(http.StatusOK).JSON().Object().Value("apps").Array()
+	apps.Length().Equal(4)
+	app := apps.Element(0).Object()
+	app.ValueEqual("slug", "valid-app-1")
+	app.ValueEqual("latestVersion", "1.0.10")
+	app.ValueEqual("versions", 4)
+
+	versions := e.GET("/apps/valid-app-1/versions").Expect().Status(http.StatusOK).
+		JSON().Object().Value("versions").Array()
+	got := []string{}
+	for _, version := range versions.Iter() {
+		record := version.Object().Value("record").String().Raw()
+		for _, line := range strings.Split(record, "\n") {
+			if strings.HasPrefix(line, "version: ") {
+				got = append(got, strings.TrimPrefix(line, "version: "))
+			}
+		}
+	}
+	require.Equal(t, []string{"1.0.10", "1.0.2", "1.0.2-rc.1", "1.0.1"}, got)
+
+	e.GET("/apps/unknown/versions").Expect().Status(http.StatusNotFound)
+}
+
+func TestSearchLatestOnly(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	newer := strings.Replace(string(record1), "version: 1.0.1", "version: 2.0.0", 1)
+	e.POST("/records").WithJSON(server.CreateRequest{Record: newer}).Expect().Status(http.StatusCreated)
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "company", Query: "Upbound Inc."},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("records").Array().Length().Equal(5)
+
+	search.LatestOnly = true
+	records := e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("records").Array()
+	records.Length().Equal(4)
+	records.Element(0).String().Equal(newer)
+}
+
+func TestLabels(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	labelled := strings.Replace(string(record1), "version: 1.0.1", "version: 2.0.0", 1) +
+		"labels:\n  team: platform\n  tier: critical\nannotations:\n  docs: https://docs.example.com\n"
+	e.POST("/records").WithJSON(server.CreateRequest{Record: labelled}).Expect().Status(http.StatusCreated)
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "labels", Query: "tier in (critical,high),team!=legacy"},
+		{Field: "company", Query: "Upbound Inc."},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("records", []string{labelled})
+
+	search.Mode = api.SearchModeStrict
+	search.SearchTerms[0].Query = "tier in (critical"
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusBadRequest)
+}
+
+func TestLicenses(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	invalid := strings.Replace(string(record1), "license: Apache-2.0", "license: Apache 2", 1)
+	e.POST("/records").WithJSON(server.CreateRequest{Record: invalid}).
+		Expect().
+		Status(http.StatusBadRequest).
+		Body().Contains("License")
+
+	dual := strings.Replace(string(record1), "license: Apache-2.0", "license: mit or apache-2.0", 1)
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: dual}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	e.GET(fmt.Sprintf("/records/%s", id)).Expect().Status(http.StatusOK).
+		JSON().Object().Value("record").String().Contains("license: MIT OR Apache-2.0")
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "license", Query: "MIT"},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("ids", []string{id})
+}
+
+func TestLicensePolicy(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t, server.WithStoreOptions(store.WithLicensePolicy(store.LicensePolicy{
+		Denied:      []string{"AGPL-3.0-only"},
+		NeedsReview: []string{"Apache-2.0"},
+	})))
+	e := httpexpect.New(t, testServer.URL)
+
+	denied := strings.Replace(string(record1), "license: Apache-2.0", "license: AGPL-3.0-only", 1)
+	e.POST("/records").WithJSON(server.CreateRequest{Record: denied}).
+		Expect().
+		Status(http.StatusUnprocessableEntity).
+		Body().Contains("AGPL-3.0-only")
+
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	report := e.GET(fmt.Sprintf("/records/%s/compliance", id)).Expect().Status(http.StatusOK).JSON().Object()
+	report.ValueEqual("status", "needsReview")
+	report.ValueEqual("licenses", []api.LicenseCompliance{{License: "Apache-2.0", Status: api.ComplianceStatusNeedsReview}})
+	e.GET("/records/missing/compliance").Expect().Status(http.StatusNotFound)
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "compliance", Query: "needsReview"},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("ids", []string{id})
+}
+
+func TestSourceURLs(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	ftp := strings.Replace(string(record1), "source: https://github.com/upbound/repo", "source: ftp://x", 1)
+	ftp = strings.Replace(ftp, "version: 1.0.1", "version: 2.0.0", 1)
+	e.POST("/records").WithJSON(server.CreateRequest{Record: ftp}).
+		Expect().
+		Status(http.StatusBadRequest).
+		Body().Contains("scheme")
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "sourceHost", Query: "github.com"},
+		{Field: "sourceOwner", Query: "Upbound"},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("ids").Array().Length().Equal(4)
+	e.GET("/fields/sourceOwner/values").Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("values", []api.FieldValue{{Value: "upbound", Count: 4}})
+}
+
+func TestRecordDescription(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	markdown := strings.Replace(string(record1), "description: This is description twoForTesting",
+		"description: |\n  ### Why app 1 is the best\n  Because it **simply** is <script>alert(1)</script>\n", 1)
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: markdown}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+
+	res := e.GET(fmt.Sprintf("/records/%s/description.html", id)).Expect().Status(http.StatusOK)
+	res.ContentType("text/html", "utf-8")
+	res.Header("Content-Security-Policy").Equal("default-src 'none'")
+	res.Body().Equal("<h3>Why app 1 is the best</h3>\n" +
+		"<p>Because it <strong>simply</strong> is &lt;script&gt;alert(1)&lt;/script&gt;</p>\n")
+	e.GET("/records/missing/description.html").Expect().Status(http.StatusNotFound)
+}
+
+func TestMaintainers(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	maintainers := e.GET("/maintainers").Expect().Status(http.StatusOK).
+		JSON().Object().Value("maintainers").Array()
+	maintainers.Length().Equal(2)
+	maintainers.Element(0).Object().ValueEqual("email", "man1@mail.com")
+	maintainers.Element(0).Object().ValueEqual("names", []string{"Maintainer One"})
+	maintainers.Element(0).Object().Value("recordIds").Array().Length().Equal(2)
+
+	maintainer := e.GET("/maintainers/MAN2@mail.com").Expect().Status(http.StatusOK).JSON().Object()
+	maintainer.ValueEqual("email", "man2@mail.com")
+	maintainer.ValueEqual("names", []string{"Maintainer Two"})
+	records := maintainer.Value("records").Array()
+	records.Length().Equal(2)
+	records.Element(0).Object().Value("record").String().Contains("title: Valid App 3")
+
+	e.GET("/maintainers/unknown@mail.com").Expect().Status(http.StatusNotFound)
+
+	search := server.SearchRequest{JoinMethod: "and", Mode: api.SearchModeStrict, SearchTerms: []api.SearchTerm{
+		{Field: "maintainer", Query: "Maintainer Two <man2@mail.com>"},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("ids").Array().Length().Equal(2)
+	search.SearchTerms[0].Query = "Maintainer Two <man1@mail.com>"
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().Value("ids").Array().Empty()
+	search.SearchTerms[0].Query = "man2@mail.com"
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusBadRequest)
+}
+
+func TestCustomFields(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	e.POST("/admin/fields").WithJSON(api.CustomField{Name: "title", Type: api.CustomFieldTypeString}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.POST("/admin/fields").WithJSON(api.CustomField{
+		Name:       "securityContact",
+		Type:       api.CustomFieldTypeString,
+		Required:   true,
+		Validation: "email",
+		Index:      api.CustomFieldIndexExact,
+	}).Expect().Status(http.StatusCreated).JSON().Object().ValueEqual("name", "securityContact")
+	e.GET("/admin/fields").Expect().Status(http.StatusOK).
+		JSON().Object().Value("fields").Array().Length().Equal(1)
+
+	e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusBadRequest).
+		Body().Contains("securityContact")
+	withContact := string(record1) + "securityContact: security@upbound.io\n"
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: withContact}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	e.GET(fmt.Sprintf("/records/%s", id)).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("record", withContact)
+
+	search := server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: "securityContact", Query: "security@upbound.io"},
+	}}
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("ids", []string{id})
+	e.GET("/fields/securityContact/values").Expect().Status(http.StatusOK)
+
+	schema := e.GET("/schema").Expect().Status(http.StatusOK).JSON().Object()
+	schema.Value("required").Array().Contains("title", "securityContact")
+	schema.Path("$.properties.securityContact.format").Equal("email")
+
+	e.POST("/searches").WithJSON(server.CreateSavedSearchRequest{Name: "contact", Request: search}).
+		Expect().
+		Status(http.StatusCreated)
+	subID := e.POST("/subscriptions").WithJSON(server.CreateSubscriptionRequest{Request: search}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	e.DELETE("/admin/fields/securityContact").Expect().Status(http.StatusConflict).
+		Body().Contains("saved search contact").Contains("subscription " + subID)
+	e.DELETE("/searches/contact").Expect().Status(http.StatusNoContent)
+	e.DELETE("/admin/fields/securityContact").Expect().Status(http.StatusConflict)
+	e.DELETE(fmt.Sprintf("/subscriptions/%s", subID)).Expect().Status(http.StatusNoContent)
+
+	e.DELETE("/admin/fields/securityContact").Expect().Status(http.StatusNoContent)
+	e.GET("/admin/fields/securityContact").Expect().Status(http.StatusNotFound)
+	e.POST("/records/search").WithJSON(search).Expect().Status(http.StatusBadRequest)
+}
+
+func TestSchemaVersions(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	e.GET("/schema/versions").Expect().Status(http.StatusOK).JSON().Object().
+		ValueEqual("current", api.APIVersionV1).
+		ValueEqual("versions", []string{api.APIVersionV1Alpha1, api.APIVersionV1}).
+		Path("$.schemas.v1alpha1").Equal("/schema?version=v1alpha1")
+	e.GET("/schema").WithQuery("version", api.APIVersionV1Alpha1).Expect().Status(http.StatusOK).JSON().
+		Path("$.properties.apiVersion.enum").Equal([]string{api.APIVersionV1Alpha1})
+	e.GET("/schema").WithQuery("version", "v3").Expect().Status(http.StatusNotFound)
+
+	legacy := `apiVersion: v1alpha1
+title: Legacy App
+version: 0.1.0
+maintainers:
+  - Maintainer One <man1@mail.com>
+company: Upbound Inc.
+website: https://website1.io
+repository: https://github.com/upbound/repo
+license: Apache-2.0
+description: Described with the draft format
+`
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: legacy}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	e.GET(fmt.Sprintf("/records/%s", id)).Expect().Status(http.StatusOK).
+		JSON().Object().Value("record").String().
+		Contains("apiVersion: v1\n").
+		Contains("  - name: Maintainer One\n    email: man1@mail.com\n").
+		Contains("source: https://github.com/upbound/repo\n")
+
+	e.POST("/records").WithJSON(server.CreateRequest{Record: strings.Replace(legacy, "v1alpha1", "v3", 1)}).
+		Expect().
+		Status(http.StatusBadRequest)
+}
+
+func TestChanges(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	e.GET("/changes").WithQuery("since", "latest").Expect().Status(http.StatusBadRequest)
+
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+
+	stream := openEventStream(t, testServer.URL+"/changes", "")
+
+	// Changes made while streaming should be delivered as well.
+	e.PUT(fmt.Sprintf("/records/%s", id)).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusOK)
+	e.DELETE(fmt.Sprintf("/records/%s", id)).Expect().Status(http.StatusNoContent)
+
+	expected := []struct {
+		op     string
+		record string
+	}{
+		{op: "create", record: string(record1)},
+		{op: "update", record: string(record2)},
+		{op: "delete", record: ""},
+	}
+	for i, exp := range expected {
+		event := readEvent(t, stream)
+		require.Equal(t, fmt.Sprint(i+1), event.id)
+		require.Equal(t, exp.op, event.event)
+
+		change := server.ChangeEvent{}
+		require.NoError(t, json.Unmarshal([]byte(event.data), &change))
+		require.Equal(t, uint64(i+1), change.Seq)
+		require.Equal(t, id, change.RecordID)
+		require.Equal(t, exp.record, change.Record)
+	}
+
+	// Resuming should skip the changes that were already received.
+	stream = openEventStream(t, testServer.URL+"/changes?since=2", "")
+	event := readEvent(t, stream)
+	require.Equal(t, "3", event.id)
+	require.Equal(t, "delete", event.event)
+}
+
+func TestChangesExpired(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t, server.WithStoreOptions(store.WithChangeLogCapacity(2)))
+	e := httpexpect.New(t, testServer.URL)
+
+	// Create, update and delete discard the first change.
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	e.PUT(fmt.Sprintf("/records/%s", id)).WithJSON(server.UpdateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusOK)
+	e.DELETE(fmt.Sprintf("/records/%s", id)).Expect().Status(http.StatusNoContent)
+
+	e.GET("/changes").WithQuery("since", "0").Expect().Status(http.StatusGone)
+
+	// Clients without a position start from the oldest change that is still kept.
+	stream := openEventStream(t, testServer.URL+"/changes", "")
+	event := readEvent(t, stream)
+	require.Equal(t, "2", event.id)
+	require.Equal(t, "update", event.event)
+
+	// Search results carry the last change so clients can follow the feed from them.
+	e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: api.SearchJoinMethodOR, SearchTerms: []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "Valid App 1"},
+	}}).
+		Expect().
+		Status(http.StatusOK).
+		Header("X-Last-Change-Seq").Equal("3")
+}
+
+func TestWebhooks(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	deliveries := make(chan delivery, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header, body: body}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(receiver.Close)
+
+	testServer := createServer(t, server.WithWebhookConfig(webhook.Config{
+		Workers:        1,
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+	}))
+	e := httpexpect.New(t, testServer.URL)
+
+	e.POST("/webhooks").WithJSON(server.CreateWebhookRequest{URL: receiver.URL}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	hook := e.POST("/webhooks").
+		WithJSON(server.CreateWebhookRequest{URL: receiver.URL, Secret: "s3cr3t", Events: []api.ChangeOp{"create"}}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object()
+	hook.NotContainsKey("secret")
+	e.GET("/webhooks").Expect().Status(http.StatusOK).
+		JSON().Object().Value("webhooks").Array().Length().Equal(1)
+
+	id := e.POST("/records").WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+
+	var d delivery
+	select {
+	case d = <-deliveries:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the webhook should receive the change")
+	}
+	require.Equal(t, webhook.Sign("s3cr3t", d.body), d.header.Get(webhook.SignatureHeader))
+	change := server.ChangeEvent{}
+	require.NoError(t, json.Unmarshal(d.body, &change))
+	require.Equal(t, id, change.RecordID)
+	require.Equal(t, string(record1), change.Record)
+
+	e.DELETE("/webhooks/" + hook.Value("id").String().Raw()).Expect().Status(http.StatusNoContent)
+
+	// Failed deliveries end up in the dead letter list.
+	e.POST("/webhooks").WithJSON(server.CreateWebhookRequest{URL: receiver.URL + "/broken", Secret: "s3cr3t"}).
+		Expect().
+		Status(http.StatusCreated)
+	e.DELETE("/records/" + id).Expect().Status(http.StatusNoContent)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-deliveries:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the broken webhook should be retried")
+		}
+	}
+	require.Eventually(t, func() bool {
+		res := e.GET("/admin/webhooks/deadletters").Expect().Status(http.StatusOK)
+		return len(res.JSON().Object().Value("deadLetters").Array().Iter()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRecordHistory(t *testing.T) {
+	record1, err := os.ReadFile(record1Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+	record2, err := os.ReadFile(record2Fp)
+	require.NoError(t, err, "testdata file should be able to be opened successfully.")
+
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+
+	id := e.POST("/records").WithHeader("X-Author", "alice").
+		WithJSON(server.CreateRequest{Record: string(record1)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").String().Raw()
+	recordPath := fmt.Sprintf("/records/%s", id)
+
+	e.PUT(recordPath).WithJSON(server.UpdateRequest{Record: string(record2)}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ValueEqual("revision", 2)
+	e.DELETE(recordPath).WithHeader("X-Author", "bob").Expect().Status(http.StatusNoContent)
+
+	revisions := e.GET(recordPath + "/history").Expect().Status(http.StatusOK).
+		JSON().Object().Value("revisions").Array()
+	revisions.Length().Equal(3)
+	revisions.Element(0).Object().ValueEqual("op", "create").ValueEqual("author", "alice")
+	revisions.Element(1).Object().ValueEqual("op", "update").ValueEqual("author", "anonymous")
+	revisions.Element(2).Object().ValueEqual("op", "delete").ValueEqual("author", "bob")
+
+	e.GET(recordPath).Expect().Status(http.StatusNotFound)
+	e.GET(recordPath).WithQuery("revision", 1).Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("revision", 1).ValueEqual("record", string(record1))
+	e.GET(recordPath).WithQuery("revision", 3).Expect().Status(http.StatusNotFound)
+	e.GET(recordPath).WithQuery("revision", "first").Expect().Status(http.StatusBadRequest)
+	e.GET(recordPath).WithQuery("at", "2000-01-01T00:00:00Z").Expect().Status(http.StatusNotFound)
+	e.GET(recordPath).WithQuery("at", time.Now().Add(time.Hour).Format(time.RFC3339)).
+		Expect().
+		Status(http.StatusNotFound)
+
+	changes := e.GET(recordPath+"/diff").WithQuery("from", 1).WithQuery("to", 2).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("changes").Array()
+	changes.Element(0).Object().
+		ValueEqual("field", "title").
+		ValueEqual("from", "Valid App 1").
+		ValueEqual("to", "Valid App 2")
+	e.GET(recordPath+"/diff").WithQuery("from", 1).WithQuery("to", 9).Expect().Status(http.StatusNotFound)
+}
+
+func TestAnalyzers(t *testing.T) {
+	testServer := createServer(t)
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	search := func() *httpexpect.Array {
+		return e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: "and",
+			SearchTerms: []api.SearchTerm{{Field: api.SearchFieldDescription, Query: "ForTest"}}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("records").Array()
+	}
+
+	analyzers := e.GET("/admin/analyzers").Expect().Status(http.StatusOK).JSON().Object()
+	analyzers.Value("available").Array().Contains(store.AnalyzerEnglish, store.AnalyzerNgram)
+	analyzers.ValueEqual("fields", []api.FieldAnalyzer{
+		{Field: api.SearchFieldDescription, Analyzer: store.AnalyzerStandard},
+	})
+	search().Empty()
+
+	e.PUT("/admin/analyzers/description").WithJSON(server.SetAnalyzerRequest{Analyzer: store.AnalyzerNgram}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().ValueEqual("analyzer", store.AnalyzerNgram)
+	search().Length().Equal(4)
+
+	e.PUT("/admin/analyzers/description").WithJSON(server.SetAnalyzerRequest{Analyzer: "french"}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.PUT("/admin/analyzers/title").WithJSON(server.SetAnalyzerRequest{Analyzer: store.AnalyzerEnglish}).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestIndexStrategies(t *testing.T) {
+	testServer := createServer(t, server.WithStoreOptions(
+		store.WithIndexStrategy(api.SearchFieldTitle, store.IndexBoth),
+		store.WithIndexStrategy(api.SearchFieldCompany, store.IndexBoth),
+	))
+	e := httpexpect.New(t, testServer.URL)
+	createRecords(t, e)
+
+	search := func(terms ...api.SearchTerm) *httpexpect.Array {
+		return e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: "and", Mode: "strict", SearchTerms: terms}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("records").Array()
+	}
+
+	search(api.SearchTerm{Field: api.SearchFieldCompany, Query: "upbound"}).Empty()
+	search(api.SearchTerm{Field: api.SearchFieldCompany, Query: "upbound", Match: api.MatchModeFullText}).Length().Equal(4)
+	search(
+		api.SearchTerm{Field: api.SearchFieldCompany, Query: "Upbound Inc.", Match: api.MatchModeExact},
+		api.SearchTerm{Field: api.SearchFieldTitle, Query: "app 1", Match: api.MatchModeFullText},
+	).Length().Equal(4)
+
+	e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: "and", SearchTerms: []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "app", Match: "fuzzy"},
+	}}).Expect().Status(http.StatusBadRequest)
+	e.POST("/records/search").WithJSON(server.SearchRequest{JoinMethod: "and", Mode: "strict", SearchTerms: []api.SearchTerm{
+		{Field: api.SearchFieldVersion, Query: "1.0.1", Match: api.MatchModeFullText},
+	}}).Expect().Status(http.StatusBadRequest).Body().Contains("does not support fullText matches")
+}
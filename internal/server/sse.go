@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Interval between comments sent on idle streams so intermediaries don't close the connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseWriter writes Server-Sent Events to a response.
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter writes the headers of an event stream response.
+// Returns an error if the response doesn't support streaming.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+// event writes a single event with data encoded as json.
+func (s *sseWriter) event(id uint64, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "id: %d\nevent: %s\ndata: %s\n\n", id, name, payload)
+	if err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// heartbeat writes a comment, which is ignored by clients.
+func (s *sseWriter) heartbeat() error {
+	_, err := fmt.Fprint(s.w, ": heartbeat\n\n")
+	if err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// lastEventID returns the sequence number a stream should be resumed from.
+// Browsers send the Last-Event-ID header when reconnecting, the lastEventId query string
+// parameter is supported for clients that can't set headers.
+func lastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("lastEventId")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, errors.New("the last event id must be a non negative integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/gorilla/mux"
+)
+
+// Name of the events sent to subscription streams.
+const subscriptionMatchEvent = "match"
+
+type CreateSubscriptionRequest struct {
+	Request SearchRequest `json:"request"`
+}
+
+type SubscriptionsResponse struct {
+	Subscriptions []api.Subscription `json:"subscriptions"`
+}
+
+// SubscriptionMatch is the data of the events sent to subscription streams.
+type SubscriptionMatch struct {
+	SubscriptionID string `json:"subscriptionId"`
+	Record         string `json:"record"`
+}
+
+func subscriptionErrStatus(err error) int {
+	if errors.Is(err, store.ErrSubscriptionNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *handler) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req CreateSubscriptionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validateSearchRequest(req.Request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.Store.CreateSubscription(req.Request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, &sub)
+}
+
+func (h *handler) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &SubscriptionsResponse{Subscriptions: h.Store.Subscriptions()})
+}
+
+func (h *handler) handleGetSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.Store.Subscription(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), subscriptionErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &sub)
+}
+
+func (h *handler) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	err := h.Store.DeleteSubscription(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), subscriptionErrStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSubscriptionEvents streams the records that match a subscription as Server-Sent Events.
+// Streams end when the client disconnects or the subscription is deleted.
+func (h *handler) handleSubscriptionEvents(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	lastSeq, err := lastEventID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Fail before writing the stream headers if the subscription doesn't exist.
+	events, next, done, err := h.Store.SubscriptionEvents(id, lastSeq)
+	if err != nil {
+		http.Error(w, err.Error(), subscriptionErrStatus(err))
+		return
+	}
+
+	stream, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		for _, event := range events {
+			rawRecord, err := marshalRecord(event.Record)
+			if err != nil {
+				return
+			}
+			err = stream.event(event.Seq, subscriptionMatchEvent, &SubscriptionMatch{
+				SubscriptionID: id,
+				Record:         rawRecord,
+			})
+			// The client is gone.
+			if err != nil {
+				return
+			}
+			lastSeq = event.Seq
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-done:
+			return
+		case <-heartbeat.C:
+			if err := stream.heartbeat(); err != nil {
+				return
+			}
+			events = nil
+			continue
+		case <-next:
+		}
+
+		events, next, done, err = h.Store.SubscriptionEvents(id, lastSeq)
+		if err != nil {
+			return
+		}
+	}
+}
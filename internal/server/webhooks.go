@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/webhook"
+	"github.com/gorilla/mux"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// Secret is used to sign the payloads, it is never returned by the api.
+	Secret string         `json:"secret"`
+	Events []api.ChangeOp `json:"events"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []api.Webhook `json:"webhooks"`
+}
+
+type DeadLettersResponse struct {
+	DeadLetters []api.DeadLetter `json:"deadLetters"`
+}
+
+func webhookErrStatus(err error) int {
+	if errors.Is(err, webhook.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func (h *handler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hook, err := h.Webhooks.Register(req.URL, req.Secret, req.Events)
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, &hook)
+}
+
+func (h *handler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &WebhooksResponse{Webhooks: h.Webhooks.Webhooks()})
+}
+
+func (h *handler) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := h.Webhooks.Webhook(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, &hook)
+}
+
+func (h *handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := h.Webhooks.Unregister(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) handleWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &DeadLettersResponse{DeadLetters: h.Webhooks.DeadLetters()})
+}
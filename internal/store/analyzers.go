@@ -0,0 +1,191 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/markdown"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/ngram"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+const (
+	// AnalyzerStandard splits the text in words, lowercases them and removes english stop words.
+	AnalyzerStandard = standard.Name
+	// AnalyzerEnglish is the standard analyzer with english stemming, e.g. "running" matches "runs".
+	AnalyzerEnglish = en.AnalyzerName
+	// AnalyzerKeyword keeps the whole value as a single term so only the exact value matches.
+	AnalyzerKeyword = keyword.Name
+	// AnalyzerNgram splits the lowercased words in trigrams so parts of words can be matched.
+	AnalyzerNgram = "ngram"
+
+	ngramFilterName = "ngram3"
+	ngramSize       = 3
+)
+
+var (
+	ErrInvalidAnalyzer = errors.New("the analyzer is not supported")
+	ErrNotFullText     = errors.New("the field does not have a full text index")
+)
+
+// Analyzers returns the names of the supported analyzers.
+func Analyzers() []string {
+	return []string{AnalyzerStandard, AnalyzerEnglish, AnalyzerKeyword, AnalyzerNgram}
+}
+
+func validateAnalyzer(analyzer string) error {
+	for _, name := range Analyzers() {
+		if analyzer == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidAnalyzer, analyzer)
+}
+
+// newIndexMapping creates a bleve mapping that analyzes every value with the named analyzer.
+func newIndexMapping(analyzer string) (mapping.IndexMapping, error) {
+	if err := validateAnalyzer(analyzer); err != nil {
+		return nil, err
+	}
+	m := bleve.NewIndexMapping()
+	if analyzer == AnalyzerNgram {
+		err := m.AddCustomTokenFilter(ngramFilterName, map[string]interface{}{
+			"type": ngram.Name,
+			"min":  float64(ngramSize),
+			"max":  float64(ngramSize),
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = m.AddCustomAnalyzer(AnalyzerNgram, map[string]interface{}{
+			"type":          custom.Name,
+			"tokenizer":     unicode.Name,
+			"token_filters": []string{lowercase.Name, ngramFilterName},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.DefaultAnalyzer = analyzer
+	return m, nil
+}
+
+// validateAnalyzers ensures the configured analyzers exist and are only set on full text
+// fields. Names that are not built in fields are applied to custom fields when registered.
+func validateAnalyzers(analyzers map[api.SearchField]string, strategies map[api.SearchField]IndexStrategy) error {
+	for field, analyzer := range analyzers {
+		if err := validateAnalyzer(analyzer); err != nil {
+			return err
+		}
+		if field.IsValid() == nil && !strategyOf(field, strategies).hasFullText() {
+			return fmt.Errorf("%w: %s", ErrNotFullText, field)
+		}
+	}
+	return nil
+}
+
+// analyzerOf returns the analyzer configured for a field, the caller must hold the store lock.
+func (s *Store) analyzerOf(field api.SearchField) string {
+	if analyzer, ok := s.analyzers[field]; ok {
+		return analyzer
+	}
+	return AnalyzerStandard
+}
+
+// fullTextIndexFor creates the full text index of a field with its configured analyzer,
+// the caller must hold the store lock.
+func (s *Store) fullTextIndexFor(field api.SearchField) (*fullTextSearchIndex, error) {
+	var preprocess func(string) string
+	if field == api.SearchFieldDescription && s.stripMarkdown {
+		preprocess = markdown.ToText
+	}
+	return newFullTextIndex(s.analyzerOf(field), preprocess)
+}
+
+// FieldAnalyzers returns the analyzer of every full text field sorted by field.
+func (s *Store) FieldAnalyzers() []api.FieldAnalyzer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	analyzers := []api.FieldAnalyzer{}
+	for field, index := range s.indexes {
+		if fullText, ok := fullTextOf(index); ok {
+			analyzers = append(analyzers, api.FieldAnalyzer{Field: field, Analyzer: fullText.analyzer})
+		}
+	}
+	sort.Slice(analyzers, func(i, j int) bool {
+		return analyzers[i].Field < analyzers[j].Field
+	})
+	return analyzers
+}
+
+// SetAnalyzer changes the analyzer of a full text field. A new index is built with every
+// record in the store and swapped for the current one, which keeps serving searches if
+// reindexing fails.
+func (s *Store) SetAnalyzer(field api.SearchField, analyzer string) (api.FieldAnalyzer, error) {
+	if err := validateAnalyzer(analyzer); err != nil {
+		return api.FieldAnalyzer{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := fullTextOf(s.indexes[field])
+	if !ok {
+		return api.FieldAnalyzer{}, fmt.Errorf("%w: %s", ErrNotFullText, field)
+	}
+	previous, configured := s.analyzers[field]
+	s.analyzers[field] = analyzer
+	index, err := s.fullTextIndexFor(field)
+	if err == nil {
+		if err = s.reindex(index, field); err != nil {
+			_ = index.bleveIndex.Close()
+		}
+	}
+	if err != nil {
+		if configured {
+			s.analyzers[field] = previous
+		} else {
+			delete(s.analyzers, field)
+		}
+		return api.FieldAnalyzer{}, err
+	}
+
+	if dual, ok := s.indexes[field].(*dualIndex); ok {
+		dual.fullText = index
+	} else {
+		s.indexes[field] = index
+	}
+	// The old index is no longer reachable, closing it releases the bleve resources.
+	_ = current.bleveIndex.Close()
+	return api.FieldAnalyzer{Field: field, Analyzer: analyzer}, nil
+}
+
+// reindex adds the values every record has for a field to an index,
+// the caller must hold the store lock.
+func (s *Store) reindex(index storeIndex, field api.SearchField) error {
+	for _, record := range s.allRecords() {
+		entries, err := s.indexEntries(record)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.field != field {
+				continue
+			}
+			if err := index.Index(record, entry.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullTextAnalyzers(t *testing.T) {
+	data := "Running applications in Kubernetes"
+	tests := []struct {
+		analyzer   string
+		matches    []string
+		nonMatches []string
+	}{
+		{AnalyzerStandard, []string{"running", "KUBERNETES"}, []string{"runs", "kube"}},
+		{AnalyzerEnglish, []string{"runs", "application"}, []string{"kube"}},
+		{AnalyzerKeyword, []string{data}, []string{"running", "running applications in kubernetes"}},
+		{AnalyzerNgram, []string{"kube", "plicat", "ERNET"}, []string{"kubex", "runs"}},
+	}
+	for _, test := range tests {
+		index, err := newFullTextIndex(test.analyzer, nil)
+		require.NoError(t, err)
+		record := &api.MetaRecord{}
+		require.NoError(t, index.Index(record, data))
+		for _, query := range test.matches {
+			results, err := index.Search(query)
+			require.NoError(t, err)
+			require.Equal(t, []*api.MetaRecord{record}, results, "%s should match %q", test.analyzer, query)
+		}
+		for _, query := range test.nonMatches {
+			results, err := index.Search(query)
+			require.NoError(t, err)
+			require.Empty(t, results, "%s should not match %q", test.analyzer, query)
+		}
+	}
+
+	_, err := newFullTextIndex("french", nil)
+	require.ErrorIs(t, err, ErrInvalidAnalyzer)
+}
+
+func TestSetAnalyzer(t *testing.T) {
+	s := newTestStore(t)
+	search := func(query string) int {
+		result, err := s.Search(api.SearchJoinMethodAND,
+			[]api.SearchTerm{{Field: api.SearchFieldDescription, Query: query}}, SearchOptions{})
+		require.NoError(t, err)
+		return len(result.Records)
+	}
+	require.Equal(t, []api.FieldAnalyzer{{Field: api.SearchFieldDescription, Analyzer: AnalyzerStandard}},
+		s.FieldAnalyzers())
+	require.Zero(t, search("descriptions"))
+
+	analyzer, err := s.SetAnalyzer(api.SearchFieldDescription, AnalyzerEnglish)
+	require.NoError(t, err)
+	require.Equal(t, api.FieldAnalyzer{Field: api.SearchFieldDescription, Analyzer: AnalyzerEnglish}, analyzer)
+	require.Equal(t, 1, search("descriptions"), "the existing records should be reindexed")
+	require.Equal(t, []api.FieldAnalyzer{analyzer}, s.FieldAnalyzers())
+
+	_, err = s.SetAnalyzer(api.SearchFieldDescription, "french")
+	require.ErrorIs(t, err, ErrInvalidAnalyzer)
+	_, err = s.SetAnalyzer(api.SearchFieldCompany, AnalyzerEnglish)
+	require.ErrorIs(t, err, ErrNotFullText)
+	require.Equal(t, 1, search("descriptions"), "failed changes should keep the current index")
+
+	_, err = s.RegisterCustomField(api.CustomField{Name: "notes", Type: api.CustomFieldTypeString,
+		Index: api.CustomFieldIndexFullText})
+	require.NoError(t, err)
+	_, err = s.SetAnalyzer("notes", AnalyzerNgram)
+	require.NoError(t, err, "custom full text fields should support analyzers")
+}
+
+func TestWithAnalyzer(t *testing.T) {
+	s, err := New(WithAnalyzer(api.SearchFieldDescription, AnalyzerNgram), WithAnalyzer("notes", AnalyzerKeyword))
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "notes", Type: api.CustomFieldTypeString,
+		Index: api.CustomFieldIndexFullText})
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldAnalyzer{
+		{Field: api.SearchFieldDescription, Analyzer: AnalyzerNgram},
+		{Field: "notes", Analyzer: AnalyzerKeyword},
+	}, s.FieldAnalyzers())
+
+	_, err = New(WithAnalyzer(api.SearchFieldDescription, "french"))
+	require.ErrorIs(t, err, ErrInvalidAnalyzer)
+	_, err = New(WithAnalyzer(api.SearchFieldTitle, AnalyzerEnglish))
+	require.ErrorIs(t, err, ErrNotFullText)
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/semver"
+)
+
+var ErrAppNotFound = errors.New("an application with the provided slug does not exist")
+
+// addToApp groups a record with the other versions of its application,
+// the caller must hold the write lock.
+func (s *Store) addToApp(record *api.MetaRecord) {
+	slug := record.AppSlug()
+	ids, ok := s.apps[slug]
+	if !ok {
+		ids = map[string]struct{}{}
+		s.apps[slug] = ids
+	}
+	ids[record.ID] = struct{}{}
+}
+
+// removeFromApp undoes addToApp, the caller must hold the write lock.
+func (s *Store) removeFromApp(record *api.MetaRecord) {
+	slug := record.AppSlug()
+	delete(s.apps[slug], record.ID)
+	if len(s.apps[slug]) == 0 {
+		delete(s.apps, slug)
+	}
+}
+
+// appVersions returns the records of an application sorted from the newest to the oldest
+// version, the caller must hold the store lock.
+func (s *Store) appVersions(slug string) []*api.MetaRecord {
+	records := []*api.MetaRecord{}
+	for id := range s.apps[slug] {
+		records = append(records, s.records[id])
+	}
+	sortByVersion(records)
+	return records
+}
+
+// sortByVersion sorts records from the highest to the lowest semantic version.
+func sortByVersion(records []*api.MetaRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		return isNewerVersion(records[i], records[j])
+	})
+}
+
+// latestVersions keeps the highest version of every application in a list of records,
+// in the position of the first record of the application.
+func latestVersions(records []*api.MetaRecord) []*api.MetaRecord {
+	latest := map[string]*api.MetaRecord{}
+	for _, record := range records {
+		slug := record.AppSlug()
+		current, ok := latest[slug]
+		if !ok || isNewerVersion(record, current) {
+			latest[slug] = record
+		}
+	}
+
+	collapsed := []*api.MetaRecord{}
+	for _, record := range records {
+		slug := record.AppSlug()
+		if keep, ok := latest[slug]; ok {
+			collapsed = append(collapsed, keep)
+			delete(latest, slug)
+		}
+	}
+	return collapsed
+}
+
+// isNewerVersion reports whether a has a higher version than b.
+// Ids are ulids, which increase over time, so of two equal versions the most recently
+// created record is the newer one.
+func isNewerVersion(a *api.MetaRecord, b *api.MetaRecord) bool {
+	if c := semver.CompareStrings(a.Version, b.Version); c != 0 {
+		return c > 0
+	}
+	return a.ID > b.ID
+}
+
+// Apps returns every application with its latest version, sorted by slug.
+func (s *Store) Apps() []api.App {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	apps := []api.App{}
+	for slug := range s.apps {
+		versions := s.appVersions(slug)
+		latest := versions[0]
+		apps = append(apps, api.App{
+			Slug:          slug,
+			Title:         latest.Title,
+			LatestVersion: latest.Version,
+			LatestID:      latest.ID,
+			Versions:      len(versions),
+		})
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Slug < apps[j].Slug
+	})
+	return apps
+}
+
+// AppVersions returns the records of an application sorted from the newest to the oldest version.
+func (s *Store) AppVersions(slug string) ([]*api.MetaRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.apps[slug]; !ok {
+		return nil, ErrAppNotFound
+	}
+	return s.appVersions(slug), nil
+}
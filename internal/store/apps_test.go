@@ -0,0 +1,104 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	require.Equal(t, "valid-app-1", api.Slugify("Valid App 1"))
+	require.Equal(t, "my-app", api.Slugify("  My -- App! "))
+	require.Equal(t, "", api.Slugify("!!!"))
+}
+
+func TestApps(t *testing.T) {
+	s := newTestStore(t)
+	v10, err := s.Append(testRecord(t, fields{"version": "0.0.10"}), WriteOptions{})
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "0.0.2"}), WriteOptions{})
+	require.NoError(t, err)
+	// An explicit slug takes precedence over the title.
+	_, err = s.Append(testRecord(t, fields{"version": "1.0.0", "title": "Renamed", "slug": "valid-app-1"}), WriteOptions{})
+	require.NoError(t, err)
+
+	apps := s.Apps()
+	require.Len(t, apps, 2)
+	require.Equal(t, "valid-app-1", apps[0].Slug)
+	require.Equal(t, "Renamed", apps[0].Title, "the title should be the one of the latest version")
+	require.Equal(t, "1.0.0", apps[0].LatestVersion)
+	require.Equal(t, 4, apps[0].Versions)
+	require.Equal(t, "valid-app-2", apps[1].Slug)
+
+	versions, err := s.AppVersions("valid-app-1")
+	require.NoError(t, err)
+	got := []string{}
+	for _, record := range versions {
+		got = append(got, record.Version)
+	}
+	require.Equal(t, []string{"1.0.0", "0.0.10", "0.0.2", "0.0.1"}, got, "versions should be sorted by semver")
+
+	// Records move between applications when their slug changes and are removed on delete.
+	_, err = s.Update(versions[0].ID, testRecord(t, fields{"version": "1.0.0", "title": "Other"}), WriteOptions{})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(v10.ID, WriteOptions{}))
+	versions, err = s.AppVersions("valid-app-1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, "0.0.2", versions[0].Version)
+	_, err = s.AppVersions("other")
+	require.NoError(t, err)
+	_, err = s.AppVersions("unknown")
+	require.Equal(t, ErrAppNotFound, err)
+}
+
+func TestSearchLatestOnly(t *testing.T) {
+	s := newTestStore(t)
+	for _, version := range []string{"0.1.0", "0.0.9"} {
+		_, err := s.Append(testRecord(t, fields{"version": version}), WriteOptions{})
+		require.NoError(t, err)
+	}
+
+	terms := []api.SearchTerm{
+		{Field: api.SearchFieldLicense, Query: "Apache-2.0"},
+		{Field: api.SearchFieldVersion, Query: "0.1.0"},
+	}
+	result, err := s.Search(api.SearchJoinMethodOR, terms, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 4)
+
+	result, err = s.Search(api.SearchJoinMethodOR, terms, SearchOptions{LatestOnly: true})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2, "only one version of every application should be returned")
+	require.Equal(t, "Valid App 1", result.Records[0].Title)
+	require.Equal(t, "0.1.0", result.Records[0].Version)
+	require.Equal(t, "Valid App 2", result.Records[1].Title)
+
+	// Collapsing happens after joining so the newest version is the newest among the matches.
+	result, err = s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "Valid App 1"},
+		{Field: api.SearchFieldVersion, Query: "0.0.9"},
+	}, SearchOptions{LatestOnly: true})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	require.Equal(t, "0.0.9", result.Records[0].Version)
+}
+
+func TestLatestVersionTie(t *testing.T) {
+	// Without a natural key the same version can be appended twice.
+	s, err := New(WithNaturalKey())
+	require.NoError(t, err)
+	data := testRecord(t, nil)
+	_, err = s.Append(data, WriteOptions{})
+	require.NoError(t, err)
+	second, err := s.Append(data, WriteOptions{})
+	require.NoError(t, err)
+
+	result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "Valid App 1"},
+	}, SearchOptions{LatestOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{second}, result.Records, "the most recently created record should win ties")
+	require.Equal(t, second.ID, s.Apps()[0].LatestID)
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+// Default number of changes that can be replayed by the change feed.
+const defaultChangeLogCapacity = 10000
+
+var (
+	ErrChangesExpired           = errors.New("the requested changes are no longer retained, a full resync is needed")
+	ErrInvalidChangeLogCapacity = errors.New("the change log must keep at least one change")
+)
+
+// recordChange adds a mutation to the history of the record and to the change log.
+// The caller must hold the store write lock so the order of the changes matches the order
+// in which they were committed to the indexes.
+func (s *Store) recordChange(op api.ChangeOp, id string, record *api.MetaRecord, author string) {
+	revision := api.Revision{
+		Revision:  len(s.history[id]) + 1,
+		Op:        op,
+		Author:    author,
+		Timestamp: time.Now().UTC(),
+		Record:    record,
+	}
+	s.history[id] = append(s.history[id], revision)
+	if record != nil {
+		record.Revision = revision.Revision
+	}
+
+	s.changes.append(api.Change{
+		Op:        op,
+		RecordID:  id,
+		Revision:  revision.Revision,
+		Author:    author,
+		Timestamp: revision.Timestamp,
+		Record:    record,
+	})
+}
+
+// Changes returns the changes with a sequence number greater than seq and a channel that is
+// closed once a newer change is available.
+// Returns ErrChangesExpired if some of the requested changes are no longer retained.
+func (s *Store) Changes(seq uint64) ([]api.Change, <-chan struct{}, error) {
+	events, next := s.changes.since(seq)
+	// Sequence numbers have no gaps so a missing change means it was discarded.
+	if len(events) > 0 && events[0].Seq != seq+1 {
+		return nil, nil, ErrChangesExpired
+	}
+
+	changes := []api.Change{}
+	for _, event := range events {
+		change := event.Payload.(api.Change)
+		change.Seq = event.Seq
+		changes = append(changes, change)
+	}
+	return changes, next, nil
+}
+
+// OldestChange returns the oldest sequence number the changes can be requested from,
+// it is 0 until the change log discards its first change.
+func (s *Store) OldestChange() uint64 {
+	return s.changes.first()
+}
+
+// LastChange returns the sequence number of the last change, 0 if there were none.
+func (s *Store) LastChange() uint64 {
+	return s.changes.last()
+}
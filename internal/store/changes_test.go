@@ -0,0 +1,134 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAndDelete(t *testing.T) {
+	s := newTestStore(t)
+
+	result, err := s.Search(api.SearchJoinMethodOR, []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "Valid App 2"},
+	}, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	id := result.Records[0].ID
+
+	data := testRecord(t, nil)
+
+	_, err = s.Update("unknown", data, WriteOptions{})
+	require.Equal(t, ErrRecordNotFound, err)
+	_, err = s.Update(id, []byte("title: [invalid"), WriteOptions{})
+	require.Equal(t, ErrUnparsable, err)
+	_, err = s.Update(id, data, WriteOptions{})
+	require.ErrorIs(t, err, ErrRecordExists, "updates should not duplicate the natural key of another record")
+
+	data = testRecord(t, fields{"version": "0.0.2"})
+	updated, err := s.Update(id, data, WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, updated.ID, "updates should keep the id of the record")
+	require.Equal(t, "Valid App 1", updated.Title)
+
+	record, err := s.Get(id)
+	require.NoError(t, err)
+	require.Equal(t, updated, record)
+
+	values, err := s.FieldValues(api.SearchFieldTitle)
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldValue{{Value: "Valid App 1", Count: 2}}, values,
+		"the previous version should be removed from the indexes")
+
+	require.NoError(t, s.Delete(id, WriteOptions{}))
+	_, err = s.Get(id)
+	require.Equal(t, ErrRecordNotFound, err)
+	require.Equal(t, ErrRecordNotFound, s.Delete(id, WriteOptions{}))
+
+	values, err = s.FieldValues(api.SearchFieldMaintainerEmail)
+	require.NoError(t, err)
+	require.Len(t, values, 2, "deleted records should be removed from the indexes")
+}
+
+func TestChanges(t *testing.T) {
+	s := newTestStore(t)
+	require.Equal(t, uint64(2), s.LastChange())
+	require.Equal(t, uint64(0), s.OldestChange())
+
+	changes, next, err := s.Changes(0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, uint64(1), changes[0].Seq)
+	require.Equal(t, api.ChangeOp(api.ChangeOpCreate), changes[0].Op)
+	require.Equal(t, changes[0].RecordID, changes[0].Record.ID)
+
+	require.NoError(t, s.Delete(changes[0].RecordID, WriteOptions{}))
+	select {
+	case <-next:
+	default:
+		require.Fail(t, "change readers should be notified of new changes")
+	}
+
+	changes, _, err = s.Changes(2)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, api.ChangeOp(api.ChangeOpDelete), changes[0].Op)
+	require.Nil(t, changes[0].Record, "deletes should not have a record")
+
+	// Shrink the log to force changes to be discarded.
+	s.changes = newEventLog(1)
+	s.changes.lastSeq = 3
+	s.recordChange(api.ChangeOpCreate, "id", nil, "")
+	s.recordChange(api.ChangeOpCreate, "id", nil, "")
+	_, _, err = s.Changes(3)
+	require.Equal(t, ErrChangesExpired, err, "discarded changes can't be replayed")
+	require.Equal(t, uint64(4), s.OldestChange())
+	changes, _, err = s.Changes(4)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
+func TestChangeLogCapacity(t *testing.T) {
+	_, err := New(WithChangeLogCapacity(0))
+	require.ErrorIs(t, err, ErrInvalidChangeLogCapacity)
+
+	s, err := New(WithChangeLogCapacity(1))
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, nil), WriteOptions{})
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "0.0.2"}), WriteOptions{})
+	require.NoError(t, err)
+	_, _, err = s.Changes(0)
+	require.ErrorIs(t, err, ErrChangesExpired)
+	changes, _, err := s.Changes(s.OldestChange())
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, uint64(2), changes[0].Seq)
+}
+
+func TestIfMatch(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	data := testRecord(t, nil)
+	record, err := s.Append(data, WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, record.Revision)
+
+	isRevision := func(expected int) func(int) bool {
+		return func(revision int) bool { return revision == expected }
+	}
+
+	_, err = s.Update(record.ID, data, WriteOptions{IfMatch: isRevision(2)})
+	require.Equal(t, ErrPreconditionFailed, err)
+	updated, err := s.Update(record.ID, data, WriteOptions{IfMatch: isRevision(1)})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Revision)
+
+	// The record was modified after revision 1 was read.
+	require.Equal(t, ErrPreconditionFailed, s.Delete(record.ID, WriteOptions{IfMatch: isRevision(1)}))
+	_, err = s.Get(record.ID)
+	require.NoError(t, err, "a failed precondition should not delete the record")
+	require.NoError(t, s.Delete(record.ID, WriteOptions{IfMatch: isRevision(2)}))
+}
@@ -0,0 +1,271 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+var (
+	ErrCustomFieldExists   = errors.New("a custom field with the provided name already exists")
+	ErrCustomFieldNotFound = errors.New("a custom field with the provided name does not exist")
+	ErrInvalidCustomField  = errors.New("the custom field definition is invalid")
+	ErrCustomFieldConflict = errors.New("the existing records don't satisfy the custom field")
+	ErrCustomFieldInUse    = errors.New("the custom field is used by saved searches or subscriptions")
+)
+
+var customFieldNameRegexp = regexp.MustCompile(`^[a-z][A-Za-z0-9]{0,62}$`)
+
+// validateCustomFieldDefinition ensures a custom field can be added to the schema.
+func validateCustomFieldDefinition(field api.CustomField) error {
+	if !customFieldNameRegexp.MatchString(field.Name) {
+		return fmt.Errorf("%w: names must be camel case alphanumeric identifiers", ErrInvalidCustomField)
+	}
+	// Custom fields share the namespace of the search fields when they are indexed.
+	if api.IsRecordField(field.Name) || api.SearchField(field.Name).IsValid() == nil {
+		return fmt.Errorf("%w: %s is a built in field", ErrInvalidCustomField, field.Name)
+	}
+	if err := field.Type.IsValid(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCustomField, err)
+	}
+	switch field.Index {
+	case "", api.CustomFieldIndexExact, api.CustomFieldIndexFullText, api.CustomFieldIndexBoth:
+	default:
+		return fmt.Errorf("%w: invalid index kind %s", ErrInvalidCustomField, field.Index)
+	}
+	if field.Validation != "" {
+		if _, err := validateVar(zeroValue(field.Type), field.Validation); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidCustomField, err)
+		}
+	}
+	return nil
+}
+
+func zeroValue(t api.CustomFieldType) interface{} {
+	switch t {
+	case api.CustomFieldTypeNumber:
+		return 0
+	case api.CustomFieldTypeBoolean:
+		return false
+	}
+	return ""
+}
+
+// validateVar runs a validation rule against a value. The validator panics with rules that
+// use unknown tags so the panic is turned into an error.
+func validateVar(value interface{}, rule string) (valid bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid validation rule %q: %v", rule, r)
+		}
+	}()
+	return validate.Var(value, rule) == nil, nil
+}
+
+// hasType reports whether a decoded yaml value has the type of a custom field.
+func hasType(value interface{}, t api.CustomFieldType) bool {
+	switch t {
+	case api.CustomFieldTypeString:
+		_, ok := value.(string)
+		return ok
+	case api.CustomFieldTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case api.CustomFieldTypeNumber:
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+	}
+	return false
+}
+
+// validateCustomValue checks the value a record has for a custom field.
+func validateCustomValue(field api.CustomField, record *api.MetaRecord) error {
+	value, ok := record.Custom[field.Name]
+	if !ok || value == nil || value == "" {
+		if field.Required {
+			return fmt.Errorf("the custom field %s is required", field.Name)
+		}
+		return nil
+	}
+	if !hasType(value, field.Type) {
+		return fmt.Errorf("the custom field %s must be a %s", field.Name, field.Type)
+	}
+	if field.Validation != "" {
+		// The rule was checked when the field was registered.
+		if valid, _ := validateVar(value, field.Validation); !valid {
+			return fmt.Errorf("the custom field %s does not satisfy %q", field.Name, field.Validation)
+		}
+	}
+	return nil
+}
+
+// validateCustomFields checks a record against every registered custom field,
+// the caller must hold the store lock.
+func (s *Store) validateCustomFields(record *api.MetaRecord) error {
+	for _, name := range s.customFieldNames() {
+		if err := validateCustomValue(s.customFields[name], record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customFieldNames returns the names of the registered custom fields sorted so validation
+// errors are deterministic, the caller must hold the store lock.
+func (s *Store) customFieldNames() []string {
+	names := make([]string, 0, len(s.customFields))
+	for name := range s.customFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// customEntries returns the index entries of the indexed custom fields of a record,
+// the caller must hold the store lock.
+func (s *Store) customEntries(record *api.MetaRecord) []indexEntry {
+	entries := []indexEntry{}
+	for _, name := range s.customFieldNames() {
+		field := s.customFields[name]
+		value, ok := record.Custom[name]
+		if field.Index == "" || !ok || value == nil || value == "" {
+			continue
+		}
+		entries = append(entries, indexEntry{field: api.SearchField(name), value: fmt.Sprint(value)})
+	}
+	return entries
+}
+
+// RegisterCustomField adds a field to the records schema. Every existing record must
+// satisfy it and, if the field is indexed, the existing values are indexed right away.
+func (s *Store) RegisterCustomField(field api.CustomField) (api.CustomField, error) {
+	if err := validateCustomFieldDefinition(field); err != nil {
+		return api.CustomField{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.customFields[field.Name]; ok {
+		return api.CustomField{}, ErrCustomFieldExists
+	}
+	for _, record := range s.allRecords() {
+		if err := validateCustomValue(field, record); err != nil {
+			return api.CustomField{}, fmt.Errorf("%w: record %s: %s", ErrCustomFieldConflict, record.ID, err)
+		}
+	}
+
+	field.CreatedAt = time.Now().UTC()
+	if field.Index != "" {
+		// The custom field index kinds have the same values as the index strategies.
+		index, err := s.indexFor(api.SearchField(field.Name), IndexStrategy(field.Index))
+		if err != nil {
+			return api.CustomField{}, err
+		}
+		for _, record := range s.allRecords() {
+			value, ok := record.Custom[field.Name]
+			if !ok || value == nil || value == "" {
+				continue
+			}
+			// The index is discarded if any record fails to be indexed.
+			if err := index.Index(record, fmt.Sprint(value)); err != nil {
+				return api.CustomField{}, err
+			}
+		}
+		s.indexes[api.SearchField(field.Name)] = index
+	}
+	s.customFields[field.Name] = field
+	return field, nil
+}
+
+// DeleteCustomField removes a field from the records schema along with its index.
+// The values are kept in the records. Fields used by saved searches or subscriptions
+// can't be removed because their terms could no longer be resolved.
+func (s *Store) DeleteCustomField(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.customFields[name]; !ok {
+		return ErrCustomFieldNotFound
+	}
+	if users := s.fieldUsers(api.SearchField(name)); len(users) > 0 {
+		return fmt.Errorf("%w: %s", ErrCustomFieldInUse, strings.Join(users, ", "))
+	}
+	delete(s.customFields, name)
+	delete(s.indexes, api.SearchField(name))
+	return nil
+}
+
+// fieldUsers describes the saved searches and subscriptions with terms on a field, the
+// caller must hold the store lock.
+func (s *Store) fieldUsers(field api.SearchField) []string {
+	uses := func(req api.SearchRequest) bool {
+		for _, term := range req.SearchTerms {
+			if term.Field == field {
+				return true
+			}
+		}
+		return false
+	}
+
+	users := []string{}
+	s.searchesMu.RLock()
+	for name, search := range s.savedSearches {
+		if uses(search.Request) {
+			users = append(users, "saved search "+name)
+		}
+	}
+	s.searchesMu.RUnlock()
+	s.subscriptions.mu.RLock()
+	for id, sub := range s.subscriptions.byID {
+		if uses(sub.Request) {
+			users = append(users, "subscription "+id)
+		}
+	}
+	s.subscriptions.mu.RUnlock()
+	sort.Strings(users)
+	return users
+}
+
+func (s *Store) CustomField(name string) (api.CustomField, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	field, ok := s.customFields[name]
+	if !ok {
+		return api.CustomField{}, ErrCustomFieldNotFound
+	}
+	return field, nil
+}
+
+// CustomFields returns the registered custom fields sorted by name.
+func (s *Store) CustomFields() []api.CustomField {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fields := []api.CustomField{}
+	for _, name := range s.customFieldNames() {
+		fields = append(fields, s.customFields[name])
+	}
+	return fields
+}
+
+// IsSearchable reports whether a field can be used in search terms, either because it
+// is a built in search field or an indexed custom field.
+func (s *Store) IsSearchable(field api.SearchField) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.indexes[field]
+	return ok
+}
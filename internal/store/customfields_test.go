@@ -0,0 +1,105 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFieldDefinitions(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	for _, invalid := range []api.CustomField{
+		{Name: "Security Contact", Type: api.CustomFieldTypeString},
+		{Name: "title", Type: api.CustomFieldTypeString},
+		{Name: "maintainerEmail", Type: api.CustomFieldTypeString},
+		{Name: "securityContact", Type: "date"},
+		{Name: "securityContact", Type: api.CustomFieldTypeString, Index: "fuzzy"},
+		{Name: "securityContact", Type: api.CustomFieldTypeString, Validation: "unknownrule"},
+	} {
+		_, err := s.RegisterCustomField(invalid)
+		require.ErrorIs(t, err, ErrInvalidCustomField, invalid)
+	}
+
+	field, err := s.RegisterCustomField(api.CustomField{Name: "securityContact", Type: api.CustomFieldTypeString})
+	require.NoError(t, err)
+	require.False(t, field.CreatedAt.IsZero())
+	_, err = s.RegisterCustomField(field)
+	require.Equal(t, ErrCustomFieldExists, err)
+	require.Equal(t, []api.CustomField{field}, s.CustomFields())
+
+	require.NoError(t, s.DeleteCustomField(field.Name))
+	require.Equal(t, ErrCustomFieldNotFound, s.DeleteCustomField(field.Name))
+}
+
+func TestCustomFields(t *testing.T) {
+	s := newTestStore(t)
+
+	// Unknown fields are kept so they can be validated once a field is registered.
+	gold, err := s.Append(testRecord(t, fields{"version": "1.0.0", "supportTier": "gold", "replicas": 3}), WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"supportTier": "gold", "replicas": uint64(3)}, gold.Custom)
+	raw, err := yaml.Marshal(gold)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(raw), "replicas: 3\nsupportTier: gold\n"),
+		"custom fields should be encoded after the fixed ones")
+
+	_, err = s.RegisterCustomField(api.CustomField{Name: "supportTier", Type: api.CustomFieldTypeString, Required: true})
+	require.ErrorIs(t, err, ErrCustomFieldConflict, "the records without the field should fail the registration")
+
+	supportTier, err := s.RegisterCustomField(api.CustomField{
+		Name:       "supportTier",
+		Type:       api.CustomFieldTypeString,
+		Validation: "oneof=gold silver",
+		Index:      api.CustomFieldIndexExact,
+	})
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "replicas", Type: api.CustomFieldTypeNumber, Validation: "min=1"})
+	require.NoError(t, err)
+
+	_, err = s.Append(testRecord(t, fields{"version": "2.0.0", "supportTier": "bronze"}), WriteOptions{})
+	require.Error(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "2.0.0", "supportTier": "gold", "replicas": "none"}), WriteOptions{})
+	require.Error(t, err, "the type of the value should be validated")
+	silver, err := s.Append(testRecord(t, fields{"version": "2.0.0", "supportTier": "silver", "replicas": 1}), WriteOptions{})
+	require.NoError(t, err)
+
+	// Existing values were indexed when the field was registered.
+	result, err := s.Search(api.SearchJoinMethodOR, []api.SearchTerm{
+		{Field: api.SearchField(supportTier.Name), Query: "gold"},
+		{Field: api.SearchField(supportTier.Name), Query: "silver"},
+	}, SearchOptions{Strict: true})
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{gold, silver}, result.Records)
+
+	require.NoError(t, s.Delete(silver.ID, WriteOptions{}))
+	values, err := s.FieldValues(api.SearchField(supportTier.Name))
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldValue{{Value: "gold", Count: 1}}, values)
+
+	tierSearch := api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodAND,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchField(supportTier.Name), Query: "gold"}},
+	}
+	_, err = s.CreateSavedSearch("gold", tierSearch)
+	require.NoError(t, err)
+	sub, err := s.CreateSubscription(tierSearch)
+	require.NoError(t, err)
+	err = s.DeleteCustomField(supportTier.Name)
+	require.ErrorIs(t, err, ErrCustomFieldInUse)
+	require.EqualError(t, err, ErrCustomFieldInUse.Error()+": saved search gold, subscription "+sub.ID)
+	require.NoError(t, s.DeleteSavedSearch("gold"))
+	require.ErrorIs(t, s.DeleteCustomField(supportTier.Name), ErrCustomFieldInUse,
+		"fields used only by subscriptions can't be removed either")
+	require.NoError(t, s.DeleteSubscription(sub.ID))
+	require.True(t, s.IsSearchable(api.SearchField(supportTier.Name)), "rejected deletes should keep the field")
+
+	require.NoError(t, s.DeleteCustomField(supportTier.Name))
+	require.False(t, s.IsSearchable(api.SearchField(supportTier.Name)))
+	_, err = s.Append(testRecord(t, fields{"version": "3.0.0", "supportTier": "bronze"}), WriteOptions{})
+	require.NoError(t, err, "deleted fields should not be validated anymore")
+}
@@ -0,0 +1,83 @@
+package store
+
+import "sync"
+
+// Default number of events kept by an event log, older events are discarded
+// and can't be replayed.
+const defaultEventLogCapacity = 1000
+
+// logEvent is an event with its sequence number.
+type logEvent struct {
+	Seq     uint64
+	Payload interface{}
+}
+
+// eventLog is an in memory, bounded and append only log of events identified by
+// monotonically increasing sequence numbers starting at 1.
+// Readers can wait for new events without polling.
+type eventLog struct {
+	mu       sync.Mutex
+	events   []logEvent
+	lastSeq  uint64
+	capacity int
+	// notify is closed and replaced every time an event is appended.
+	notify chan struct{}
+}
+
+func newEventLog(capacity int) *eventLog {
+	return &eventLog{
+		capacity: capacity,
+		notify:   make(chan struct{}),
+	}
+}
+
+// append adds an event to the log, wakes up all the waiting readers and returns the
+// sequence number assigned to the event.
+func (l *eventLog) append(payload interface{}) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastSeq += 1
+	l.events = append(l.events, logEvent{Seq: l.lastSeq, Payload: payload})
+	if len(l.events) > l.capacity {
+		// Copy the retained events so the discarded ones can be garbage collected.
+		l.events = append([]logEvent{}, l.events[len(l.events)-l.capacity:]...)
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+	return l.lastSeq
+}
+
+// since returns the retained events with a sequence number greater than seq and
+// a channel that is closed once a newer event is appended.
+func (l *eventLog) since(seq uint64) ([]logEvent, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := []logEvent{}
+	for _, event := range l.events {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events, l.notify
+}
+
+// first returns the sequence number preceding the oldest retained event, which is the
+// last one if the log is empty.
+func (l *eventLog) first() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) == 0 {
+		return l.lastSeq
+	}
+	return l.events[0].Seq - 1
+}
+
+// last returns the sequence number of the last appended event, 0 if the log is empty.
+func (l *eventLog) last() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastSeq
+}
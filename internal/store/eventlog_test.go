@@ -0,0 +1,33 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLog(t *testing.T) {
+	log := newEventLog(2)
+	require.Equal(t, uint64(0), log.last())
+
+	events, next := log.since(0)
+	require.Empty(t, events)
+
+	require.Equal(t, uint64(1), log.append("first"))
+	select {
+	case <-next:
+	default:
+		require.Fail(t, "readers should be notified when an event is appended")
+	}
+
+	log.append("second")
+	log.append("third")
+	require.Equal(t, uint64(3), log.last())
+
+	events, _ = log.since(0)
+	require.Equal(t, []logEvent{{Seq: 2, Payload: "second"}, {Seq: 3, Payload: "third"}}, events,
+		"only the newest events should be retained")
+
+	events, _ = log.since(2)
+	require.Equal(t, []logEvent{{Seq: 3, Payload: "third"}}, events)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/goccy/go-yaml"
+)
+
+var ErrRevisionNotFound = errors.New("the requested revision of the record does not exist")
+
+// History returns every revision of a record, oldest first.
+// Deleted records keep their history.
+func (s *Store) History(id string) ([]api.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions, ok := s.history[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return append([]api.Revision{}, revisions...), nil
+}
+
+// Revision returns a specific revision of a record.
+func (s *Store) Revision(id string, revision int) (api.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.revision(id, revision)
+}
+
+// revision is the implementation of Revision, the caller must hold the store lock.
+func (s *Store) revision(id string, revision int) (api.Revision, error) {
+	revisions, ok := s.history[id]
+	if !ok {
+		return api.Revision{}, ErrRecordNotFound
+	}
+	// Revisions are numbered from 1 and stored in order.
+	if revision < 1 || revision > len(revisions) {
+		return api.Revision{}, ErrRevisionNotFound
+	}
+	return revisions[revision-1], nil
+}
+
+// RevisionAt returns the revision of a record that was current at the provided time.
+func (s *Store) RevisionAt(id string, at time.Time) (api.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions, ok := s.history[id]
+	if !ok {
+		return api.Revision{}, ErrRecordNotFound
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if !revisions[i].Timestamp.After(at) {
+			return revisions[i], nil
+		}
+	}
+	// The record didn't exist yet.
+	return api.Revision{}, ErrRevisionNotFound
+}
+
+// Diff returns the top level fields that differ between two revisions of a record.
+func (s *Store) Diff(id string, from int, to int) ([]api.FieldDiff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fromRevision, err := s.revision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.revision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffRecords(fromRevision.Record, toRevision.Record)
+}
+
+// diffRecords compares the yaml documents of two records field by field, in document order.
+// A nil record is treated as an empty document.
+func diffRecords(from *api.MetaRecord, to *api.MetaRecord) ([]api.FieldDiff, error) {
+	fromFields, err := recordFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := recordFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromValues := map[string]string{}
+	for _, field := range fromFields {
+		fromValues[field.key] = field.value
+	}
+	toValues := map[string]string{}
+	for _, field := range toFields {
+		toValues[field.key] = field.value
+	}
+
+	diffs := []api.FieldDiff{}
+	for _, field := range fromFields {
+		if toValue, ok := toValues[field.key]; !ok || toValue != field.value {
+			diffs = append(diffs, api.FieldDiff{Field: field.key, From: field.value, To: toValue})
+		}
+	}
+	// Fields that were added by the newer revision.
+	for _, field := range toFields {
+		if _, ok := fromValues[field.key]; !ok {
+			diffs = append(diffs, api.FieldDiff{Field: field.key, To: field.value})
+		}
+	}
+	return diffs, nil
+}
+
+type recordField struct {
+	key   string
+	value string
+}
+
+// recordFields returns the top level fields of the yaml document of a record with their
+// values encoded as yaml. Plain strings are returned as is to keep diffs readable.
+func recordFields(record *api.MetaRecord) ([]recordField, error) {
+	if record == nil {
+		return nil, nil
+	}
+
+	raw, err := yaml.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	// Keep the order of nested mappings (e.g. maintainers) so the values are encoded consistently.
+	if err := yaml.UnmarshalWithOptions(raw, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+
+	fields := []recordField{}
+	for _, item := range doc {
+		key, ok := item.Key.(string)
+		if !ok {
+			continue
+		}
+		if value, ok := item.Value.(string); ok {
+			fields = append(fields, recordField{key: key, value: value})
+			continue
+		}
+		value, err := yaml.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, recordField{key: key, value: strings.TrimSuffix(string(value), "\n")})
+	}
+	return fields, nil
+}
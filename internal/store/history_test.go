@@ -0,0 +1,79 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	valid1, err := os.ReadFile(filepath.Join(validDir, "valid1.yaml"))
+	require.NoError(t, err)
+	valid2, err := os.ReadFile(filepath.Join(validDir, "valid2.yaml"))
+	require.NoError(t, err)
+
+	record, err := s.Append(valid1, WriteOptions{Author: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, 1, record.Revision)
+	beforeUpdate := time.Now().UTC()
+
+	updated, err := s.Update(record.ID, valid2, WriteOptions{Author: "bob"})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Revision)
+	require.NoError(t, s.Delete(record.ID, WriteOptions{Author: "carol"}))
+
+	revisions, err := s.History(record.ID)
+	require.NoError(t, err, "deleted records should keep their history")
+	require.Len(t, revisions, 3)
+	for i, expected := range []struct {
+		op     api.ChangeOp
+		author string
+		record *api.MetaRecord
+	}{
+		{op: api.ChangeOpCreate, author: "alice", record: record},
+		{op: api.ChangeOpUpdate, author: "bob", record: updated},
+		{op: api.ChangeOpDelete, author: "carol", record: nil},
+	} {
+		require.Equal(t, i+1, revisions[i].Revision)
+		require.Equal(t, expected.op, revisions[i].Op)
+		require.Equal(t, expected.author, revisions[i].Author)
+		require.Equal(t, expected.record, revisions[i].Record)
+	}
+
+	revision, err := s.Revision(record.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Valid App 1", revision.Record.Title, "past revisions should not be modified by updates")
+	_, err = s.Revision(record.ID, 4)
+	require.Equal(t, ErrRevisionNotFound, err)
+	_, err = s.Revision("unknown", 1)
+	require.Equal(t, ErrRecordNotFound, err)
+
+	revision, err = s.RevisionAt(record.ID, beforeUpdate)
+	require.NoError(t, err)
+	require.Equal(t, 1, revision.Revision)
+	_, err = s.RevisionAt(record.ID, revisions[0].Timestamp.Add(-time.Second))
+	require.Equal(t, ErrRevisionNotFound, err, "there are no revisions before the record was created")
+
+	diffs, err := s.Diff(record.ID, 1, 2)
+	require.NoError(t, err)
+	fields := []string{}
+	for _, diff := range diffs {
+		fields = append(fields, diff.Field)
+	}
+	require.Equal(t, []string{"title", "version", "maintainers", "company", "website", "source", "description"},
+		fields, "only the fields that changed should be returned in document order")
+	require.Equal(t, api.FieldDiff{Field: "title", From: "Valid App 1", To: "Valid App 2"}, diffs[0])
+	require.Equal(t, "- name: AppTwo Maintainer\n  email: apptwo@hotmail.com", diffs[2].To)
+
+	diffs, err = s.Diff(record.ID, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, diffs, 8, "every field should be removed by a delete")
+	require.Empty(t, diffs[0].To)
+}
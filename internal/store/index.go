@@ -2,33 +2,39 @@ package store
 
 import (
 	"errors"
-	"math/rand"
-	"time"
+	"strings"
 
 	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/common"
 	"github.com/blevesearch/bleve/v2"
-	"github.com/oklog/ulid/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+const (
+	indexKindExact    = "exact"
+	indexKindFullText = "fullText"
 )
 
 type storeIndex interface {
 	Index(*api.MetaRecord, string) error
+	// Remove undoes a single call to Index with the same arguments.
+	Remove(*api.MetaRecord, string) error
 	Search(string) ([]*api.MetaRecord, error)
+	// Matches reports whether an indexed record would be returned by Search, without looking
+	// at any other record. values are the values the record was indexed with.
+	Matches(record *api.MetaRecord, values []string, term string) (bool, error)
+	// Kind returns the type of the index, used to explain searches.
+	Kind() string
+	// NormalizeQuery returns the query as it is actually looked up by the index.
+	NormalizeQuery(string) string
+	// Values returns every distinct value that has been indexed along with
+	// the number of distinct records that were indexed with it.
+	Values() map[string]int
 }
 
 func newIndex(isFullText bool) (storeIndex, error) {
 	if isFullText {
-		mapping := bleve.NewIndexMapping()
-		bleveIndex, err := bleve.NewMemOnly(mapping)
-		if err != nil {
-			return nil, err
-		}
-
-		index := fullTextSearchIndex{
-			bleveIndex: bleveIndex,
-			idMap:      map[string]*api.MetaRecord{},
-		}
-
-		return &index, nil
+		return newFullTextIndex(AnalyzerStandard, nil)
 	}
 	return exactMatchSearchIndex{
 		mapping: map[string][]*api.MetaRecord{},
@@ -36,6 +42,28 @@ func newIndex(isFullText bool) (storeIndex, error) {
 
 }
 
+// newFullTextIndex creates a full text index that uses the named analyzer, if preprocess
+// is not nil the values are passed through it before being analyzed.
+func newFullTextIndex(analyzer string, preprocess func(string) string) (*fullTextSearchIndex, error) {
+	mapping, err := newIndexMapping(analyzer)
+	if err != nil {
+		return nil, err
+	}
+	bleveIndex, err := bleve.NewMemOnly(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullTextSearchIndex{
+		bleveIndex: bleveIndex,
+		analyzer:   analyzer,
+		preprocess: preprocess,
+		idMap:      map[string]*api.MetaRecord{},
+		values:     map[string][]*api.MetaRecord{},
+		docs:       map[*api.MetaRecord][]fullTextDoc{},
+	}, nil
+}
+
 // NOTE: Implementig a full text search would have been too much work for the purposes
 // of this challenge but I still wanted to have the feature available for the description field.
 // The bleve library is probably too overkill for this purpose, but once again, I just wanted
@@ -44,7 +72,21 @@ func newIndex(isFullText bool) (storeIndex, error) {
 type fullTextSearchIndex struct {
 	name       string
 	bleveIndex bleve.Index
+	analyzer   string
+	// preprocess transforms the values before they are indexed, e.g. to remove markup.
+	preprocess func(string) string
 	idMap      map[string]*api.MetaRecord
+	// bleve only keeps the analyzed terms, the raw values are kept separately
+	// so they can be listed.
+	values map[string][]*api.MetaRecord
+	// Used to find the bleve documents of a record when it is removed.
+	docs map[*api.MetaRecord][]fullTextDoc
+}
+
+// fullTextDoc is a document that has been indexed by bleve.
+type fullTextDoc struct {
+	id   string
+	data string
 }
 
 func (i fullTextSearchIndex) Index(record *api.MetaRecord, data string) error {
@@ -56,30 +98,53 @@ func (i fullTextSearchIndex) Index(record *api.MetaRecord, data string) error {
 	}
 	// Create a string parsable UID for the record.
 	// This is necessary because bleve only accepts strings as document identifiers.
-	t := time.Now()
-	entropy := ulid.Monotonic(rand.New(rand.NewSource(t.UnixNano())), 0)
-	recordId, err := ulid.New(ulid.Timestamp(t), entropy)
-
+	recordId, err := common.NewID()
 	if err != nil {
 		return err
 	}
 
-	i.idMap[recordId.String()] = record
-	err = i.bleveIndex.Index(recordId.String(), data)
+	i.idMap[recordId] = record
+	doc := data
+	if i.preprocess != nil {
+		doc = i.preprocess(data)
+	}
+	err = i.bleveIndex.Index(recordId, doc)
 	if err != nil {
 		return err
 	}
+	i.values[data] = append(i.values[data], record)
+	i.docs[record] = append(i.docs[record], fullTextDoc{id: recordId, data: data})
 
 	return nil
 }
 
+func (i fullTextSearchIndex) Remove(record *api.MetaRecord, data string) error {
+	docs := i.docs[record]
+	for pos, doc := range docs {
+		if doc.data != data {
+			continue
+		}
+		err := i.bleveIndex.Delete(doc.id)
+		if err != nil {
+			return err
+		}
+		delete(i.idMap, doc.id)
+		removeRecord(i.values, data, record)
+		i.docs[record] = append(docs[:pos:pos], docs[pos+1:]...)
+		if len(i.docs[record]) == 0 {
+			delete(i.docs, record)
+		}
+		return nil
+	}
+	return errors.New("the record is not indexed with the provided data")
+}
+
 func (i fullTextSearchIndex) Search(term string) ([]*api.MetaRecord, error) {
 	if term == "" {
 		return nil, errors.New("must provide a valid search term")
 	}
 	// Retireve the internal ids for the records from the bleve index.
-	query := bleve.NewMatchQuery(term)
-	search := bleve.NewSearchRequest(query)
+	search := bleve.NewSearchRequest(i.matchQuery(term))
 	searchResults, err := i.bleveIndex.Search(search)
 	if err != nil {
 		return nil, err
@@ -95,6 +160,61 @@ func (i fullTextSearchIndex) Search(term string) ([]*api.MetaRecord, error) {
 	return resultRecords, nil
 }
 
+// Matches runs the match query restricted to the documents of the record.
+func (i fullTextSearchIndex) Matches(record *api.MetaRecord, _ []string, term string) (bool, error) {
+	if term == "" {
+		return false, errors.New("must provide a valid search term")
+	}
+	ids := []string{}
+	for _, doc := range i.docs[record] {
+		ids = append(ids, doc.id)
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	search := bleve.NewSearchRequest(bleve.NewConjunctionQuery(i.matchQuery(term), bleve.NewDocIDQuery(ids)))
+	search.Size = 1
+	searchResults, err := i.bleveIndex.Search(search)
+	if err != nil {
+		return false, err
+	}
+	return searchResults.Total > 0, nil
+}
+
+// matchQuery builds the query that looks up a term.
+func (i fullTextSearchIndex) matchQuery(term string) query.Query {
+	matchQuery := bleve.NewMatchQuery(term)
+	// Any shared trigram would match otherwise, requiring all of them approximates
+	// searching for a substring.
+	if i.analyzer == AnalyzerNgram {
+		matchQuery.SetOperator(query.MatchQueryOperatorAnd)
+	}
+	return matchQuery
+}
+
+func (i fullTextSearchIndex) Kind() string {
+	return indexKindFullText
+}
+
+// NormalizeQuery runs the query through the same analyzer that is used by match queries
+// and returns the resulting tokens separated by spaces.
+func (i fullTextSearchIndex) NormalizeQuery(term string) string {
+	mapping := i.bleveIndex.Mapping()
+	analyzer := mapping.AnalyzerNamed(mapping.AnalyzerNameForPath(mapping.DefaultSearchField()))
+	if analyzer == nil {
+		return term
+	}
+	tokens := []string{}
+	for _, token := range analyzer.Analyze([]byte(term)) {
+		tokens = append(tokens, string(token.Term))
+	}
+	return strings.Join(tokens, " ")
+}
+
+func (i fullTextSearchIndex) Values() map[string]int {
+	return distinctRecordCounts(i.values)
+}
+
 // Implement an exact match index a map.
 type exactMatchSearchIndex struct {
 	mapping map[string][]*api.MetaRecord
@@ -111,9 +231,72 @@ func (i exactMatchSearchIndex) Index(record *api.MetaRecord, data string) error
 	return nil
 }
 
+func (i exactMatchSearchIndex) Remove(record *api.MetaRecord, data string) error {
+	if !removeRecord(i.mapping, data, record) {
+		return errors.New("the record is not indexed with the provided data")
+	}
+	return nil
+}
+
 func (i exactMatchSearchIndex) Search(term string) ([]*api.MetaRecord, error) {
 	if term == "" {
 		return nil, errors.New("must provide a valid search term")
 	}
 	return i.mapping[term], nil
 }
+
+func (i exactMatchSearchIndex) Matches(_ *api.MetaRecord, values []string, term string) (bool, error) {
+	if term == "" {
+		return false, errors.New("must provide a valid search term")
+	}
+	return contains(values, term), nil
+}
+
+func (i exactMatchSearchIndex) Kind() string {
+	return indexKindExact
+}
+
+// NormalizeQuery is a no-op because exact match queries are looked up as is.
+func (i exactMatchSearchIndex) NormalizeQuery(term string) string {
+	return term
+}
+
+func (i exactMatchSearchIndex) Values() map[string]int {
+	return distinctRecordCounts(i.mapping)
+}
+
+// removeRecord removes a single occurrence of record from the records associated with value.
+// Returns false if the record was not associated with the value.
+// NOTE: This is linear on the number of records that share the value.
+func removeRecord(mapping map[string][]*api.MetaRecord, value string, record *api.MetaRecord) bool {
+	records := mapping[value]
+	for pos, r := range records {
+		if r != record {
+			continue
+		}
+		// Build a new slice so slices previously returned by searches are not modified.
+		remaining := append(records[:pos:pos], records[pos+1:]...)
+		if len(remaining) == 0 {
+			delete(mapping, value)
+		} else {
+			mapping[value] = remaining
+		}
+		return true
+	}
+	return false
+}
+
+// distinctRecordCounts counts how many distinct records are associated with every value.
+// A record can be indexed more than once with the same value (e.g. two maintainers
+// sharing a name) so the length of the slices can't be used directly.
+func distinctRecordCounts(mapping map[string][]*api.MetaRecord) map[string]int {
+	counts := map[string]int{}
+	for value, records := range mapping {
+		seen := map[*api.MetaRecord]bool{}
+		for _, record := range records {
+			seen[record] = true
+		}
+		counts[value] = len(seen)
+	}
+	return counts
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/markdown"
 	"github.com/stretchr/testify/require"
 )
 
@@ -149,3 +150,70 @@ func TestExactMatchSearching(t *testing.T) {
 		require.ElementsMatchf(t, d.results, results, "index should return the correct results for the following query: %s", d.term)
 	}
 }
+
+func TestValues(t *testing.T) {
+	shared := &api.MetaRecord{}
+	for _, isFullText := range []bool{true, false} {
+		index, err := newIndex(isFullText)
+		require.NoError(t, err)
+
+		require.NoError(t, index.Index(shared, "Maintainer One"))
+		// The same record indexed twice with the same value should only be counted once.
+		require.NoError(t, index.Index(shared, "Maintainer One"))
+		require.NoError(t, index.Index(&api.MetaRecord{}, "Maintainer One"))
+		require.NoError(t, index.Index(&api.MetaRecord{}, "Maintainer Two"))
+
+		require.Equal(t, map[string]int{"Maintainer One": 2, "Maintainer Two": 1}, index.Values(),
+			"index should return the distinct values with their distinct record counts")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	for _, isFullText := range []bool{true, false} {
+		index, err := newIndex(isFullText)
+		require.NoError(t, err)
+
+		first, second := &api.MetaRecord{}, &api.MetaRecord{}
+		require.NoError(t, index.Index(first, "shared value"))
+		require.NoError(t, index.Index(first, "shared value"))
+		require.NoError(t, index.Index(second, "shared value"))
+
+		results, err := index.Search("shared value")
+		require.NoError(t, err)
+		require.Contains(t, results, first)
+
+		require.NoError(t, index.Remove(first, "shared value"))
+		require.Equal(t, map[string]int{"shared value": 2}, index.Values(),
+			"removing should only undo a single call to index")
+		require.NoError(t, index.Remove(first, "shared value"))
+
+		results, err = index.Search("shared value")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*api.MetaRecord{second}, results, "removed records should not be returned")
+		require.Error(t, index.Remove(first, "shared value"), "records that are not indexed can't be removed")
+
+		require.NoError(t, index.Remove(second, "shared value"))
+		require.Empty(t, index.Values(), "values without records should not be listed")
+	}
+}
+
+func TestFullTextPreprocess(t *testing.T) {
+	index, err := newFullTextIndex(AnalyzerStandard, markdown.ToText)
+	require.NoError(t, err)
+	record := &api.MetaRecord{}
+	data := "### Interesting Title\nRead the [docs](https://docs.example.com)"
+	require.NoError(t, index.Index(record, data))
+
+	results, err := index.Search("docs")
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{record}, results)
+	results, err = index.Search("example")
+	require.NoError(t, err)
+	require.Empty(t, results, "link destinations should not be indexed")
+	require.Equal(t, map[string]int{data: 1}, index.Values(), "the raw values should be kept")
+
+	require.NoError(t, index.Remove(record, data))
+	results, err = index.Search("docs")
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+const indexKindLabel = "label"
+
+var (
+	// Label keys and values are restricted to a charset that doesn't overlap with the
+	// selector syntax so selectors can be parsed without escaping.
+	labelKeyRegexp   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	labelValueRegexp = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+	inRegexp         = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+type selectorOp string
+
+const (
+	selectorOpEquals    selectorOp = "="
+	selectorOpNotEquals selectorOp = "!="
+	selectorOpIn        selectorOp = "in"
+	selectorOpNotIn     selectorOp = "notin"
+	selectorOpExists    selectorOp = "exists"
+	selectorOpNotExists selectorOp = "!"
+)
+
+// labelRequirement is a single condition of a label selector.
+type labelRequirement struct {
+	key    string
+	op     selectorOp
+	values []string
+}
+
+// matches reports whether a set of labels satisfies the requirement. As with kubernetes
+// selectors, negative requirements are satisfied by records that don't have the key.
+func (req labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[req.key]
+	switch req.op {
+	case selectorOpEquals, selectorOpIn:
+		return ok && contains(req.values, value)
+	case selectorOpNotEquals, selectorOpNotIn:
+		return !ok || !contains(req.values, value)
+	case selectorOpExists:
+		return ok
+	case selectorOpNotExists:
+		return !ok
+	}
+	return false
+}
+
+// positive reports whether only records that have the key can satisfy the requirement.
+func (req labelRequirement) positive() bool {
+	return req.op == selectorOpEquals || req.op == selectorOpIn || req.op == selectorOpExists
+}
+
+func (req labelRequirement) String() string {
+	switch req.op {
+	case selectorOpEquals, selectorOpNotEquals:
+		return req.key + string(req.op) + req.values[0]
+	case selectorOpIn, selectorOpNotIn:
+		return fmt.Sprintf("%s %s (%s)", req.key, req.op, strings.Join(req.values, ","))
+	case selectorOpNotExists:
+		return "!" + req.key
+	}
+	return req.key
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSelector parses a comma separated list of requirements that must all be satisfied.
+// The supported requirements are key=value (or key==value), key!=value, key in (v1,v2),
+// key notin (v1,v2), key and !key.
+func parseSelector(selector string) ([]labelRequirement, error) {
+	parts, err := splitSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := []labelRequirement{}
+	for _, part := range parts {
+		req, err := parseRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, req)
+	}
+	return requirements, nil
+}
+
+// splitSelector splits a selector on the commas that are not part of a set of values.
+func splitSelector(selector string) ([]string, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, errors.New("the label selector can't be empty")
+	}
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth += 1
+		case ')':
+			depth -= 1
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+		if depth < 0 || depth > 1 {
+			return nil, errors.New("the label selector has unbalanced parentheses")
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("the label selector has unbalanced parentheses")
+	}
+	return append(parts, selector[start:]), nil
+}
+
+func parseRequirement(raw string) (labelRequirement, error) {
+	req := labelRequirement{}
+	switch {
+	case strings.HasPrefix(raw, "!") && !strings.Contains(raw, "="):
+		req.key, req.op = strings.TrimSpace(raw[1:]), selectorOpNotExists
+	case strings.Contains(raw, "!="):
+		parts := strings.SplitN(raw, "!=", 2)
+		req.key, req.op, req.values = strings.TrimSpace(parts[0]), selectorOpNotEquals, []string{strings.TrimSpace(parts[1])}
+	case strings.Contains(raw, "="):
+		parts := strings.SplitN(raw, "=", 2)
+		value := strings.TrimPrefix(parts[1], "=")
+		req.key, req.op, req.values = strings.TrimSpace(parts[0]), selectorOpEquals, []string{strings.TrimSpace(value)}
+	case inRegexp.MatchString(raw):
+		match := inRegexp.FindStringSubmatch(raw)
+		req.key, req.op = match[1], selectorOp(match[2])
+		for _, value := range strings.Split(match[3], ",") {
+			req.values = append(req.values, strings.TrimSpace(value))
+		}
+	default:
+		req.key, req.op = raw, selectorOpExists
+	}
+
+	if !labelKeyRegexp.MatchString(req.key) {
+		return req, fmt.Errorf("invalid label key in selector requirement %q", raw)
+	}
+	for _, value := range req.values {
+		if !labelValueRegexp.MatchString(value) {
+			return req, fmt.Errorf("invalid label value in selector requirement %q", raw)
+		}
+	}
+	return req, nil
+}
+
+// labelIndex indexes records by every one of their labels. Its entries have the form
+// key=value and it is queried with label selectors.
+type labelIndex struct {
+	// keys maps every label key to its values and the records that have them.
+	keys map[string]map[string][]*api.MetaRecord
+	// all returns every record in the store, needed to resolve selectors that only have
+	// negative requirements. Only called while the store lock is held.
+	all func() []*api.MetaRecord
+}
+
+func newLabelIndex(all func() []*api.MetaRecord) *labelIndex {
+	return &labelIndex{keys: map[string]map[string][]*api.MetaRecord{}, all: all}
+}
+
+func splitLabel(data string) (string, string, error) {
+	parts := strings.SplitN(data, "=", 2)
+	if len(parts) != 2 {
+		return "", "", errors.New("label entries must have the form key=value")
+	}
+	return parts[0], parts[1], nil
+}
+
+func (i *labelIndex) Index(record *api.MetaRecord, data string) error {
+	if record == nil {
+		return errors.New("must pass a valid pointer")
+	}
+	key, value, err := splitLabel(data)
+	if err != nil {
+		return err
+	}
+	values, ok := i.keys[key]
+	if !ok {
+		values = map[string][]*api.MetaRecord{}
+		i.keys[key] = values
+	}
+	values[value] = append(values[value], record)
+	return nil
+}
+
+func (i *labelIndex) Remove(record *api.MetaRecord, data string) error {
+	key, value, err := splitLabel(data)
+	if err != nil {
+		return err
+	}
+	if !removeRecord(i.keys[key], value, record) {
+		return errors.New("the record is not indexed with the provided data")
+	}
+	if len(i.keys[key]) == 0 {
+		delete(i.keys, key)
+	}
+	return nil
+}
+
+// Search returns the records that satisfy a label selector sorted by id, which is the
+// order they were created in.
+func (i *labelIndex) Search(selector string) ([]*api.MetaRecord, error) {
+	requirements, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from the records of a positive requirement to avoid scanning every record.
+	var candidates []*api.MetaRecord
+	for _, req := range requirements {
+		if req.positive() {
+			candidates = i.withKey(req.key)
+			break
+		}
+	}
+	if candidates == nil {
+		candidates = i.all()
+	}
+
+	records := []*api.MetaRecord{}
+	for _, record := range candidates {
+		matches := true
+		for _, req := range requirements {
+			if !req.matches(record.Labels) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(a, b int) bool {
+		return records[a].ID < records[b].ID
+	})
+	return records, nil
+}
+
+// Matches evaluates the selector against the labels of the record.
+func (i *labelIndex) Matches(record *api.MetaRecord, _ []string, selector string) (bool, error) {
+	requirements, err := parseSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	for _, req := range requirements {
+		if !req.matches(record.Labels) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// withKey returns every record that has a label with the provided key.
+func (i *labelIndex) withKey(key string) []*api.MetaRecord {
+	records := []*api.MetaRecord{}
+	for _, values := range i.keys[key] {
+		records = append(records, values...)
+	}
+	return records
+}
+
+func (i *labelIndex) Kind() string {
+	return indexKindLabel
+}
+
+// NormalizeQuery returns the canonical form of a selector.
+func (i *labelIndex) NormalizeQuery(selector string) string {
+	requirements, err := parseSelector(selector)
+	if err != nil {
+		return selector
+	}
+	normalized := []string{}
+	for _, req := range requirements {
+		normalized = append(normalized, req.String())
+	}
+	return strings.Join(normalized, ",")
+}
+
+// Values returns the indexed labels in the key=value form.
+func (i *labelIndex) Values() map[string]int {
+	values := map[string]int{}
+	for key, mapping := range i.keys {
+		for value, count := range distinctRecordCounts(mapping) {
+			values[key+"="+value] = count
+		}
+	}
+	return values
+}
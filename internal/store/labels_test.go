@@ -0,0 +1,65 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	requirements, err := parseSelector("tier=critical, team!=legacy,env in (prod, staging),app==web,deprecated,!internal,zone notin (a)")
+	require.NoError(t, err)
+	require.Equal(t, []labelRequirement{
+		{key: "tier", op: selectorOpEquals, values: []string{"critical"}},
+		{key: "team", op: selectorOpNotEquals, values: []string{"legacy"}},
+		{key: "env", op: selectorOpIn, values: []string{"prod", "staging"}},
+		{key: "app", op: selectorOpEquals, values: []string{"web"}},
+		{key: "deprecated", op: selectorOpExists},
+		{key: "internal", op: selectorOpNotExists},
+		{key: "zone", op: selectorOpNotIn, values: []string{"a"}},
+	}, requirements)
+
+	for _, invalid := range []string{"", "tier=a b", "env in (prod", "env in prod)", "!=value", "tier=critical,,team=a", "env in ((a))"} {
+		_, err := parseSelector(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	s := newTestStore(t)
+	critical, err := s.Append(testRecord(t, fields{
+		"version": "1.0.0",
+		"labels":  map[string]string{"tier": "critical", "team": "platform", "env": "prod"},
+	}), WriteOptions{})
+	require.NoError(t, err)
+	legacy, err := s.Append(testRecord(t, fields{
+		"version": "2.0.0",
+		"labels":  map[string]string{"tier": "critical", "team": "legacy", "env": "staging"},
+	}), WriteOptions{})
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "3.0.0", "labels": map[string]string{"bad key": "value"}}), WriteOptions{})
+	require.Error(t, err, "label keys should be validated")
+
+	search := func(selector string) []*api.MetaRecord {
+		result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+			{Field: api.SearchFieldLabels, Query: selector},
+		}, SearchOptions{Strict: true})
+		require.NoError(t, err, selector)
+		return result.Records
+	}
+	require.Equal(t, []*api.MetaRecord{critical}, search("tier=critical,team!=legacy"))
+	require.Equal(t, []*api.MetaRecord{critical, legacy}, search("env in (prod,staging)"))
+	require.Len(t, search("team!=legacy"), 3, "records without the key should match negative requirements")
+	require.Len(t, search("!tier"), 2)
+	require.Empty(t, search("env notin (prod,staging),tier"))
+
+	values, err := s.FieldValues(api.SearchFieldLabels)
+	require.NoError(t, err)
+	require.Contains(t, values, api.FieldValue{Value: "tier=critical", Count: 2})
+
+	// Labels are removed from the index along with the record.
+	require.NoError(t, s.Delete(legacy.ID, WriteOptions{}))
+	require.Equal(t, []*api.MetaRecord{critical}, search("tier"))
+	require.Equal(t, "env in (prod,staging),!tier", s.indexes[api.SearchFieldLabels].NormalizeQuery("env in ( prod , staging ),! tier"))
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/license"
+)
+
+// licenseIndex is an exact match index of SPDX expressions. Records are indexed with their
+// whole expression and with every license in it so compound expressions can be found by
+// any of their components, e.g. "MIT OR Apache-2.0" is found when searching for "MIT".
+type licenseIndex struct {
+	exactMatchSearchIndex
+}
+
+func newLicenseIndex() *licenseIndex {
+	return &licenseIndex{exactMatchSearchIndex{mapping: map[string][]*api.MetaRecord{}}}
+}
+
+// Search looks up the canonical form of the query so it's not case sensitive.
+func (i *licenseIndex) Search(term string) ([]*api.MetaRecord, error) {
+	return i.exactMatchSearchIndex.Search(i.NormalizeQuery(term))
+}
+
+func (i *licenseIndex) Matches(record *api.MetaRecord, values []string, term string) (bool, error) {
+	return i.exactMatchSearchIndex.Matches(record, values, i.NormalizeQuery(term))
+}
+
+// NormalizeQuery returns the canonical form of the query, or the query as is if it is not
+// a valid SPDX expression, in which case it can't match any record.
+func (i *licenseIndex) NormalizeQuery(term string) string {
+	canonical, err := license.Canonicalize(term)
+	if err != nil {
+		return term
+	}
+	return canonical
+}
+
+// licenseValues returns the values the license of a record is indexed with: the whole
+// expression, every license with its exception and every bare license identifier, without
+// duplicates.
+func licenseValues(record *api.MetaRecord) []string {
+	values := []string{record.License}
+	expr, err := license.Parse(record.License)
+	// Records are validated before being indexed, this is just a safeguard.
+	if err == nil {
+		for _, l := range license.Licenses(expr) {
+			values = append(values, l.String(), l.ID)
+		}
+	}
+
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenses(t *testing.T) {
+	s := newTestStore(t)
+	dual, err := s.Append(testRecord(t, fields{"version": "1.0.0", "license": "mit or apache-2.0"}), WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "MIT OR Apache-2.0", dual.License, "licenses should be canonicalized")
+	gpl, err := s.Append(testRecord(t, fields{"version": "2.0.0", "license": "(GPL-2.0-or-later WITH Classpath-exception-2.0) AND MIT"}), WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "GPL-2.0-or-later WITH Classpath-exception-2.0 AND MIT", gpl.License)
+
+	for _, invalid := range []string{"Apache 2", "MIT OR", "Unknown-1.0"} {
+		_, err = s.Append(testRecord(t, fields{"version": "3.0.0", "license": invalid}), WriteOptions{})
+		require.Error(t, err, invalid)
+		require.Contains(t, err.Error(), "License", invalid)
+	}
+
+	search := func(query string) []*api.MetaRecord {
+		result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+			{Field: api.SearchFieldLicense, Query: query},
+		}, SearchOptions{Strict: true})
+		require.NoError(t, err, query)
+		return result.Records
+	}
+	require.Len(t, search("Apache-2.0"), 3, "components of compound expressions should match")
+	require.Equal(t, []*api.MetaRecord{dual, gpl}, search("mit"))
+	require.Equal(t, []*api.MetaRecord{dual}, search("mit OR apache-2.0"))
+	require.Equal(t, []*api.MetaRecord{gpl}, search("GPL-2.0-or-later"))
+	require.Equal(t, []*api.MetaRecord{gpl}, search("GPL-2.0-or-later with classpath-exception-2.0"))
+	require.Empty(t, search("Apache 2"))
+
+	require.NoError(t, s.Delete(gpl.ID, WriteOptions{}))
+	require.Equal(t, []*api.MetaRecord{dual}, search("MIT"))
+}
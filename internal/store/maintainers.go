@@ -0,0 +1,183 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+var (
+	ErrMaintainerNotFound     = errors.New("a maintainer with the provided email does not exist")
+	ErrInvalidMaintainerQuery = errors.New(`maintainer queries must have the form "Name <email>"`)
+)
+
+// normalizeEmail returns the form emails are compared with. The local part of an address
+// is case sensitive according to the RFC but no real provider treats it that way.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// maintainerRecords returns the records of every email in the email index, which ignores
+// case. The caller must hold the store lock.
+func (s *Store) maintainerRecords() map[string][]*api.MetaRecord {
+	index := s.indexes[api.SearchFieldMaintainerEmail]
+	grouped := map[string][]*api.MetaRecord{}
+	for email := range index.Values() {
+		// The values come from the index so the search can't fail.
+		grouped[email], _ = index.Search(email)
+	}
+	return grouped
+}
+
+// maintainerProfile builds the profile of a maintainer from the records it appears in.
+// Duplicated records are removed and the rest are sorted by id.
+func maintainerProfile(email string, records []*api.MetaRecord) (api.MaintainerProfile, []*api.MetaRecord) {
+	unique := []*api.MetaRecord{}
+	seen := map[*api.MetaRecord]bool{}
+	names := map[string]bool{}
+	for _, record := range records {
+		if seen[record] {
+			continue
+		}
+		seen[record] = true
+		unique = append(unique, record)
+		for _, maintainer := range record.Maintainers {
+			if normalizeEmail(maintainer.Email) == email {
+				names[maintainer.Name] = true
+			}
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		return unique[i].ID < unique[j].ID
+	})
+
+	profile := api.MaintainerProfile{Email: email, Names: []string{}, RecordIDs: []string{}}
+	for name := range names {
+		profile.Names = append(profile.Names, name)
+	}
+	sort.Strings(profile.Names)
+	for _, record := range unique {
+		profile.RecordIDs = append(profile.RecordIDs, record.ID)
+	}
+	return profile, unique
+}
+
+// Maintainers returns every maintainer sorted by email.
+func (s *Store) Maintainers() []api.MaintainerProfile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := []api.MaintainerProfile{}
+	for email, records := range s.maintainerRecords() {
+		profile, _ := maintainerProfile(email, records)
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Email < profiles[j].Email
+	})
+	return profiles
+}
+
+// Maintainer returns the profile of a maintainer and the records it appears in, sorted by id.
+// The email is normalized before looking it up.
+func (s *Store) Maintainer(email string) (api.MaintainerProfile, []*api.MetaRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	email = normalizeEmail(email)
+	records, err := s.indexes[api.SearchFieldMaintainerEmail].Search(email)
+	if err != nil || len(records) == 0 {
+		return api.MaintainerProfile{}, nil, ErrMaintainerNotFound
+	}
+	profile, records := maintainerProfile(email, records)
+	return profile, records, nil
+}
+
+// maintainerIndex is a composite index of the name and email of every maintainer, so a
+// single term can require both to belong to the same maintainer. Values and queries have
+// the "Name <email>" form, names are matched exactly and emails ignoring case.
+type maintainerIndex struct {
+	exactMatchSearchIndex
+}
+
+func newMaintainerIndex() *maintainerIndex {
+	return &maintainerIndex{exactMatchSearchIndex{mapping: map[string][]*api.MetaRecord{}}}
+}
+
+// splitMaintainer splits a "Name <email>" string in its trimmed name and normalized email.
+// The email is delimited by the last angle brackets so names can contain any character.
+func splitMaintainer(maintainer string) (string, string, bool) {
+	maintainer = strings.TrimSpace(maintainer)
+	open := strings.LastIndex(maintainer, "<")
+	if open < 0 || !strings.HasSuffix(maintainer, ">") {
+		return "", "", false
+	}
+	return strings.TrimSpace(maintainer[:open]), normalizeEmail(maintainer[open+1 : len(maintainer)-1]), true
+}
+
+// normalizeMaintainer returns the canonical form of a "Name <email>" query, which must
+// have both a name and an email.
+func normalizeMaintainer(maintainer string) (string, error) {
+	name, email, ok := splitMaintainer(maintainer)
+	if !ok || name == "" || email == "" {
+		return "", ErrInvalidMaintainerQuery
+	}
+	return name + " <" + email + ">", nil
+}
+
+// indexedMaintainer returns the canonical form of a maintainer of a record. Records are
+// already validated so, unlike queries, blank names are indexed as they are.
+func indexedMaintainer(maintainer string) (string, error) {
+	name, email, ok := splitMaintainer(maintainer)
+	if !ok {
+		return "", errors.New("the maintainer must have the form \"Name <email>\"")
+	}
+	return name + " <" + email + ">", nil
+}
+
+func (i *maintainerIndex) Index(record *api.MetaRecord, data string) error {
+	normalized, err := indexedMaintainer(data)
+	if err != nil {
+		return err
+	}
+	return i.exactMatchSearchIndex.Index(record, normalized)
+}
+
+func (i *maintainerIndex) Remove(record *api.MetaRecord, data string) error {
+	normalized, err := indexedMaintainer(data)
+	if err != nil {
+		return err
+	}
+	return i.exactMatchSearchIndex.Remove(record, normalized)
+}
+
+func (i *maintainerIndex) Search(term string) ([]*api.MetaRecord, error) {
+	normalized, err := normalizeMaintainer(term)
+	if err != nil {
+		return nil, err
+	}
+	return i.exactMatchSearchIndex.Search(normalized)
+}
+
+func (i *maintainerIndex) Matches(_ *api.MetaRecord, values []string, term string) (bool, error) {
+	normalized, err := normalizeMaintainer(term)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		if indexed, err := indexedMaintainer(value); err == nil && indexed == normalized {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (i *maintainerIndex) NormalizeQuery(term string) string {
+	normalized, err := normalizeMaintainer(term)
+	if err != nil {
+		return term
+	}
+	return normalized
+}
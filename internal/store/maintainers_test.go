@@ -0,0 +1,122 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintainers(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	first, err := s.Append(testRecord(t, nil), WriteOptions{})
+	require.NoError(t, err)
+	// The same maintainer with a different name and email case.
+	renamed := testRecord(t, fields{
+		"version": "0.0.2",
+		"maintainers": []map[string]string{
+			{"name": "First Maintainer", "email": "FirstMaintainer@Hotmail.com"},
+			{"name": "secondmaintainer app1", "email": "secondmaintainer@gmail.com"},
+		},
+	})
+	second, err := s.Append(renamed, WriteOptions{})
+	require.NoError(t, err)
+
+	require.Equal(t, []api.MaintainerProfile{
+		{
+			Email:     "firstmaintainer@hotmail.com",
+			Names:     []string{"First Maintainer", "firstmaintainer app1"},
+			RecordIDs: []string{first.ID, second.ID},
+		},
+		{
+			Email:     "secondmaintainer@gmail.com",
+			Names:     []string{"secondmaintainer app1"},
+			RecordIDs: []string{first.ID, second.ID},
+		},
+	}, s.Maintainers())
+
+	profile, records, err := s.Maintainer(" FIRSTMAINTAINER@hotmail.com")
+	require.NoError(t, err)
+	require.Equal(t, "firstmaintainer@hotmail.com", profile.Email)
+	require.Equal(t, []*api.MetaRecord{first, second}, records)
+
+	require.NoError(t, s.Delete(first.ID, WriteOptions{}))
+	profile, _, err = s.Maintainer("firstmaintainer@hotmail.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"First Maintainer"}, profile.Names)
+	require.NoError(t, s.Delete(second.ID, WriteOptions{}))
+	_, _, err = s.Maintainer("firstmaintainer@hotmail.com")
+	require.ErrorIs(t, err, ErrMaintainerNotFound)
+	require.Empty(t, s.Maintainers())
+}
+
+func TestMaintainerSearch(t *testing.T) {
+	s := newTestStore(t)
+	// Swap the emails so each name appears with the email of the other maintainer.
+	swapped := testRecord(t, fields{
+		"version": "0.0.2",
+		"maintainers": []map[string]string{
+			{"name": "firstmaintainer app1", "email": "secondmaintainer@gmail.com"},
+			{"name": "secondmaintainer app1", "email": "firstmaintainer@hotmail.com"},
+		},
+	})
+	record, err := s.Append(swapped, WriteOptions{})
+	require.NoError(t, err)
+
+	values, err := record.FieldValues(api.SearchFieldMaintainer)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"firstmaintainer app1 <secondmaintainer@gmail.com>",
+		"secondmaintainer app1 <firstmaintainer@hotmail.com>",
+	}, values)
+	_, err = record.FieldValueFromSearchField(api.SearchFieldMaintainerName)
+	require.ErrorIs(t, err, api.ErrMultiValuedField)
+
+	search := func(terms ...api.SearchTerm) []*api.MetaRecord {
+		result, err := s.Search(api.SearchJoinMethodAND, terms, SearchOptions{Strict: true})
+		require.NoError(t, err)
+		return result.Records
+	}
+	uncorrelated := search(
+		api.SearchTerm{Field: api.SearchFieldMaintainerName, Query: "firstmaintainer app1"},
+		api.SearchTerm{Field: api.SearchFieldMaintainerEmail, Query: "FirstMaintainer@hotmail.com"},
+	)
+	require.Len(t, uncorrelated, 2, "separate terms can match different maintainers")
+	require.NotEqual(t, record, uncorrelated[0])
+	require.Len(t, search(api.SearchTerm{Field: api.SearchFieldMaintainer, Query: "firstmaintainer app1 <FirstMaintainer@hotmail.com>"}), 1)
+	require.Equal(t, []*api.MetaRecord{record},
+		search(api.SearchTerm{Field: api.SearchFieldMaintainer, Query: "secondmaintainer app1 <firstmaintainer@hotmail.com>"}))
+
+	_, err = s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldMaintainer, Query: "firstmaintainer@hotmail.com"},
+	}, SearchOptions{Strict: true})
+	require.ErrorIs(t, err, ErrInvalidMaintainerQuery)
+}
+
+func TestBlankMaintainerName(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	blank := testRecord(t, fields{
+		"maintainers": []map[string]string{
+			{"name": "  ", "email": "firstmaintainer@hotmail.com"},
+			{"name": "secondmaintainer app1", "email": "secondmaintainer@gmail.com"},
+		},
+	})
+	record, err := s.Append(blank, WriteOptions{})
+	require.NoError(t, err, "records with blank maintainer names pass validation and must be indexed")
+
+	result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldMaintainerEmail, Query: "firstmaintainer@hotmail.com"},
+	}, SearchOptions{Strict: true})
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{record}, result.Records)
+	_, err = s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldMaintainer, Query: " <firstmaintainer@hotmail.com>"},
+	}, SearchOptions{Strict: true})
+	require.ErrorIs(t, err, ErrInvalidMaintainerQuery, "queries still need a name")
+
+	require.NoError(t, s.Delete(record.ID, WriteOptions{}))
+}
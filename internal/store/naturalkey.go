@@ -0,0 +1,54 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+var ErrRecordExists = errors.New("a record with the same natural key already exists")
+
+// DuplicateError is returned when a write would make two records share a natural key.
+type DuplicateError struct {
+	// ID of the record that already has the natural key.
+	ID string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrRecordExists, e.ID)
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrRecordExists
+}
+
+// validateNaturalKey ensures every field of a natural key has a single value per record.
+func validateNaturalKey(fields []api.SearchField) error {
+	for _, field := range fields {
+		if err := field.IsValid(); err != nil {
+			return err
+		}
+		if _, err := (&api.MetaRecord{}).FieldValueFromSearchField(field); err != nil {
+			return fmt.Errorf("field %s can't be part of the natural key: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// naturalKeyOf returns the natural key of a record, false if the store doesn't enforce one.
+func (s *Store) naturalKeyOf(record *api.MetaRecord) (string, bool) {
+	if len(s.naturalKey) == 0 {
+		return "", false
+	}
+	values := make([]string, 0, len(s.naturalKey))
+	for _, field := range s.naturalKey {
+		// The fields were validated when the store was created.
+		value, _ := record.FieldValueFromSearchField(field)
+		// Quoting prevents collisions between values that contain the separator.
+		values = append(values, strconv.Quote(value))
+	}
+	return strings.Join(values, ","), true
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNaturalKey(t *testing.T) {
+	_, err := New(WithNaturalKey(api.SearchFieldMaintainerEmail))
+	require.ErrorIs(t, err, api.ErrFieldLookupNotSupported, "multi valued fields can't be part of the key")
+	_, err = New(WithNaturalKey(api.SearchFieldSourceOwner))
+	require.ErrorIs(t, err, api.ErrDerivedField, "derived fields can't be part of the key")
+
+	s := newTestStore(t)
+	data, err := os.ReadFile(filepath.Join(validDir, "valid1.yaml"))
+	require.NoError(t, err)
+
+	_, err = s.Append(data, WriteOptions{})
+	duplicateErr := &DuplicateError{}
+	require.ErrorAs(t, err, &duplicateErr)
+	existing, err := s.Get(duplicateErr.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Valid App 1", existing.Title)
+
+	replaced, replacedExisting, err := s.Upsert(data, WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, replacedExisting)
+	require.Equal(t, existing.ID, replaced.ID, "upserts should replace the existing record")
+	require.Equal(t, 2, replaced.Revision)
+
+	// Once deleted the key can be used again.
+	require.NoError(t, s.Delete(existing.ID, WriteOptions{}))
+	created, replacedExisting, err := s.Upsert(data, WriteOptions{})
+	require.NoError(t, err)
+	require.False(t, replacedExisting, "upserts without a matching record should create one")
+	require.NotEqual(t, existing.ID, created.ID)
+	_, replacedExisting, err = s.Upsert(data, WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, replacedExisting)
+}
+
+func TestNaturalKeyDisabled(t *testing.T) {
+	s, err := New(WithNaturalKey())
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(validDir, "valid1.yaml"))
+	require.NoError(t, err)
+
+	first, err := s.Append(data, WriteOptions{})
+	require.NoError(t, err)
+	second, replaced, err := s.Upsert(data, WriteOptions{})
+	require.NoError(t, err)
+	require.False(t, replaced)
+	require.NotEqual(t, first.ID, second.ID)
+}
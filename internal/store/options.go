@@ -0,0 +1,86 @@
+package store
+
+import "github.com/AYM1607/goAKSChallenge/api"
+
+// Option configures the store.
+type Option func(*options)
+
+type options struct {
+	naturalKey    []api.SearchField
+	licensePolicy LicensePolicy
+	urlRules      map[api.SearchField]URLRule
+	stripMarkdown bool
+	analyzers     map[api.SearchField]string
+	strategies    map[api.SearchField]IndexStrategy
+	// changeLogCapacity is the number of changes kept for the change feed.
+	changeLogCapacity int
+}
+
+func defaultOptions() options {
+	return options{
+		naturalKey:        []api.SearchField{api.SearchFieldTitle, api.SearchFieldVersion},
+		urlRules:          defaultURLRules(),
+		analyzers:         map[api.SearchField]string{},
+		strategies:        map[api.SearchField]IndexStrategy{},
+		changeLogCapacity: defaultChangeLogCapacity,
+	}
+}
+
+// WithNaturalKey sets the fields that identify a record, no two records can have the
+// same values for all of them. Calling it without fields disables the constraint.
+func WithNaturalKey(fields ...api.SearchField) Option {
+	return func(o *options) {
+		o.naturalKey = fields
+	}
+}
+
+// WithLicensePolicy sets the policy the licenses of new and updated records are checked
+// against. Records with a denied license are rejected.
+func WithLicensePolicy(policy LicensePolicy) Option {
+	return func(o *options) {
+		o.licensePolicy = policy
+	}
+}
+
+// WithURLRule sets the rule the website or source field is checked against, replacing the
+// default rule that only allows the http and https schemes. A zero rule allows any url.
+func WithURLRule(field api.SearchField, rule URLRule) Option {
+	return func(o *options) {
+		o.urlRules[field] = rule
+	}
+}
+
+// WithMarkdownStripping removes the markdown syntax of descriptions before they are added
+// to the full text index so only the displayed text can be matched.
+func WithMarkdownStripping() Option {
+	return func(o *options) {
+		o.stripMarkdown = true
+	}
+}
+
+// WithAnalyzer sets the analyzer of a full text field, see Analyzers for the supported ones.
+// Fields use the standard analyzer by default. The analyzer of a custom field is applied
+// when it is registered with a full text index.
+func WithAnalyzer(field api.SearchField, analyzer string) Option {
+	return func(o *options) {
+		o.analyzers[field] = analyzer
+	}
+}
+
+// WithIndexStrategy sets how the values of a built in field are indexed. Descriptions are
+// full text and the rest of the fields are exact by default. Only the fields with plain
+// text values can be configured: title, version, maintainerName, company, website, source
+// and description.
+func WithIndexStrategy(field api.SearchField, strategy IndexStrategy) Option {
+	return func(o *options) {
+		o.strategies[field] = strategy
+	}
+}
+
+// WithChangeLogCapacity sets the number of changes kept for the change feed, older changes
+// can't be replayed. The last 10000 changes are kept by default.
+func WithChangeLogCapacity(capacity int) Option {
+	return func(o *options) {
+		o.changeLogCapacity = capacity
+	}
+}
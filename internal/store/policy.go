@@ -0,0 +1,149 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/license"
+)
+
+var (
+	ErrLicenseDenied        = errors.New("the license of the record is denied by the license policy")
+	ErrInvalidLicensePolicy = errors.New("the license policy is invalid")
+)
+
+// LicensePolicy decides which licenses records can have. Entries are SPDX license
+// identifiers, optionally with an exception (e.g. "GPL-2.0-only WITH Classpath-exception-2.0").
+// An entry without an exception also applies to the license with any exception.
+// When Allowed is empty every license that is not denied or needs review is allowed,
+// otherwise the licenses that are not listed need review.
+type LicensePolicy struct {
+	Allowed     []string
+	Denied      []string
+	NeedsReview []string
+}
+
+// licensePolicy is a LicensePolicy with its entries canonicalized.
+// The zero value allows every license.
+type licensePolicy struct {
+	allowed     map[string]bool
+	denied      map[string]bool
+	needsReview map[string]bool
+}
+
+func newLicensePolicy(p LicensePolicy) (licensePolicy, error) {
+	policy := licensePolicy{}
+	var err error
+	if policy.allowed, err = licenseSet(p.Allowed); err != nil {
+		return policy, err
+	}
+	if policy.denied, err = licenseSet(p.Denied); err != nil {
+		return policy, err
+	}
+	if policy.needsReview, err = licenseSet(p.NeedsReview); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+func licenseSet(entries []string) (map[string]bool, error) {
+	set := map[string]bool{}
+	for _, entry := range entries {
+		expr, err := license.Parse(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidLicensePolicy, err)
+		}
+		if _, ok := expr.(license.License); !ok {
+			return nil, fmt.Errorf("%w: %q is not a single license", ErrInvalidLicensePolicy, entry)
+		}
+		set[expr.String()] = true
+	}
+	return set, nil
+}
+
+// complianceRank orders the statuses from the best to the worst.
+var complianceRank = map[api.ComplianceStatus]int{
+	api.ComplianceStatusAllowed:     0,
+	api.ComplianceStatusNeedsReview: 1,
+	api.ComplianceStatusDenied:      2,
+}
+
+// evaluate returns the status of a canonical license expression and of every license in it.
+func (p licensePolicy) evaluate(expression string) (api.ComplianceStatus, []api.LicenseCompliance) {
+	expr, err := license.Parse(expression)
+	// Records are validated before being evaluated, this is just a safeguard.
+	if err != nil {
+		return api.ComplianceStatusNeedsReview, nil
+	}
+	licenses := []api.LicenseCompliance{}
+	return p.evaluateExpression(expr, &licenses), licenses
+}
+
+func (p licensePolicy) evaluateExpression(expr license.Expression, licenses *[]api.LicenseCompliance) api.ComplianceStatus {
+	switch e := expr.(type) {
+	case license.License:
+		status := p.licenseStatus(e)
+		*licenses = append(*licenses, api.LicenseCompliance{License: e.String(), Status: status})
+		return status
+	case license.Compound:
+		var result api.ComplianceStatus
+		for i, term := range e.Terms {
+			status := p.evaluateExpression(term, licenses)
+			// Any alternative of an OR expression can be chosen but every component
+			// of an AND expression applies.
+			better := complianceRank[status] < complianceRank[result]
+			if i == 0 || (e.Op == license.OpOr) == better {
+				result = status
+			}
+		}
+		return result
+	}
+	return api.ComplianceStatusNeedsReview
+}
+
+func (p licensePolicy) licenseStatus(l license.License) api.ComplianceStatus {
+	withException := l.String()
+	bare := license.License{ID: l.ID, OrLater: l.OrLater}.String()
+	inSet := func(set map[string]bool) bool {
+		return set[withException] || set[bare]
+	}
+	switch {
+	case inSet(p.denied):
+		return api.ComplianceStatusDenied
+	case inSet(p.needsReview):
+		return api.ComplianceStatusNeedsReview
+	case len(p.allowed) == 0 || inSet(p.allowed):
+		return api.ComplianceStatusAllowed
+	}
+	return api.ComplianceStatusNeedsReview
+}
+
+// checkLicensePolicy rejects records with a denied license.
+func (s *Store) checkLicensePolicy(record *api.MetaRecord) error {
+	status, licenses := s.policy.evaluate(record.License)
+	if status != api.ComplianceStatusDenied {
+		return nil
+	}
+	denied := []string{}
+	for _, l := range licenses {
+		if l.Status == api.ComplianceStatusDenied {
+			denied = append(denied, l.License)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrLicenseDenied, strings.Join(denied, ", "))
+}
+
+// Compliance returns the evaluation of the license of a record against the license policy.
+func (s *Store) Compliance(id string) (api.ComplianceReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return api.ComplianceReport{}, ErrRecordNotFound
+	}
+	status, licenses := s.policy.evaluate(record.License)
+	return api.ComplianceReport{ID: id, License: record.License, Status: status, Licenses: licenses}, nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicensePolicyEvaluate(t *testing.T) {
+	policy, err := newLicensePolicy(LicensePolicy{
+		Allowed:     []string{"MIT", "apache-2.0"},
+		Denied:      []string{"AGPL-3.0-only"},
+		NeedsReview: []string{"GPL-2.0-only"},
+	})
+	require.NoError(t, err)
+
+	for expression, expected := range map[string]api.ComplianceStatus{
+		"MIT":                                  api.ComplianceStatusAllowed,
+		"AGPL-3.0-only":                        api.ComplianceStatusDenied,
+		"GPL-2.0-only":                         api.ComplianceStatusNeedsReview,
+		"BSD-3-Clause":                         api.ComplianceStatusNeedsReview,
+		"AGPL-3.0-only OR MIT":                 api.ComplianceStatusAllowed,
+		"AGPL-3.0-only AND MIT":                api.ComplianceStatusDenied,
+		"(GPL-2.0-only OR MIT) AND Apache-2.0": api.ComplianceStatusAllowed,
+		"GPL-2.0-only AND Apache-2.0":          api.ComplianceStatusNeedsReview,
+		"GPL-2.0-only WITH Classpath-exception-2.0": api.ComplianceStatusNeedsReview,
+	} {
+		status, _ := policy.evaluate(expression)
+		require.Equal(t, expected, status, expression)
+	}
+
+	status, licenses := policy.evaluate("AGPL-3.0-only OR MIT")
+	require.Equal(t, api.ComplianceStatusAllowed, status)
+	require.Equal(t, []api.LicenseCompliance{
+		{License: "AGPL-3.0-only", Status: api.ComplianceStatusDenied},
+		{License: "MIT", Status: api.ComplianceStatusAllowed},
+	}, licenses)
+
+	status, _ = licensePolicy{}.evaluate("AGPL-3.0-only")
+	require.Equal(t, api.ComplianceStatusAllowed, status, "the zero policy should allow every license")
+
+	_, err = newLicensePolicy(LicensePolicy{Denied: []string{"MIT OR Apache-2.0"}})
+	require.ErrorIs(t, err, ErrInvalidLicensePolicy)
+	_, err = newLicensePolicy(LicensePolicy{Denied: []string{"Unknown-1.0"}})
+	require.ErrorIs(t, err, ErrInvalidLicensePolicy)
+}
+
+func TestLicensePolicy(t *testing.T) {
+	s, err := New(WithLicensePolicy(LicensePolicy{
+		Denied:      []string{"AGPL-3.0-only"},
+		NeedsReview: []string{"GPL-2.0-only"},
+	}))
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "1.0.0", "license": "AGPL-3.0-only"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrLicenseDenied)
+	allowed, err := s.Append(testRecord(t, fields{"version": "1.0.0", "license": "MIT"}), WriteOptions{})
+	require.NoError(t, err)
+	flagged, err := s.Append(testRecord(t, fields{"version": "2.0.0", "license": "GPL-2.0-only"}), WriteOptions{})
+	require.NoError(t, err)
+	_, err = s.Update(allowed.ID, testRecord(t, fields{"version": "1.0.0", "license": "AGPL-3.0-only AND MIT"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrLicenseDenied, "updates should be checked too")
+
+	report, err := s.Compliance(flagged.ID)
+	require.NoError(t, err)
+	require.Equal(t, api.ComplianceReport{
+		ID:       flagged.ID,
+		License:  "GPL-2.0-only",
+		Status:   api.ComplianceStatusNeedsReview,
+		Licenses: []api.LicenseCompliance{{License: "GPL-2.0-only", Status: api.ComplianceStatusNeedsReview}},
+	}, report)
+	_, err = s.Compliance("missing")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldCompliance, Query: string(api.ComplianceStatusNeedsReview)},
+	}, SearchOptions{Strict: true})
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{flagged}, result.Records)
+}
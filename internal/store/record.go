@@ -3,30 +3,78 @@ package store
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/license"
 	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-yaml"
 )
 
 var validate = validator.New()
 
+// slugRegexp matches lowercase alphanumeric words separated by single hyphens.
+var slugRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// patternValidations are the custom validations that match a string against a regular
+// expression, they are also published as patterns in the JSON schema.
+var patternValidations = map[string]*regexp.Regexp{
+	"slug":       slugRegexp,
+	"labelkey":   labelKeyRegexp,
+	"labelvalue": labelValueRegexp,
+}
+
+func init() {
+	for tag, pattern := range patternValidations {
+		pattern := pattern
+		// Registering a validation only fails for empty tags or nil functions.
+		_ = validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return pattern.MatchString(fl.Field().String())
+		})
+	}
+	_ = validate.RegisterValidation("spdx", func(fl validator.FieldLevel) bool {
+		_, err := license.Parse(fl.Field().String())
+		return err == nil
+	})
+	// Records without a slug are grouped by the slug of their title, which can't be empty.
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		r := sl.Current().Interface().(api.MetaRecord)
+		if r.Title != "" && r.AppSlug() == "" {
+			sl.ReportError(r.Title, "Title", "title", "slugifiable", "")
+		}
+	}, api.MetaRecord{})
+}
+
 // newRecord creates a new record from a raw stream of bytes.
 // Returns an error if either the stream is unparsable or the created rawRecord doesn't conform to the schema.
+// Records of previous versions of the schema are upgraded to the current one.
 func newRecord(rawRecord []byte) (*api.MetaRecord, error) {
+	version, err := documentAPIVersion(rawRecord)
+	if err != nil {
+		return nil, err
+	}
+	if version != api.APIVersionV1 {
+		rawRecord, err = upgradeDocument(rawRecord, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var r api.MetaRecord
 	if err := yaml.Unmarshal(rawRecord, &r); err != nil {
 		return nil, ErrUnparsable
 	}
 
-	err := validate.Struct(r)
+	err = validate.Struct(r)
 	if err != nil {
 		fieldsWithErrors := schemaErrorFields(err.(validator.ValidationErrors))
 		errString := fmt.Sprintf("the following field(s) are missing or invalid: %s",
 			strings.Join(fieldsWithErrors, ","))
 		return nil, errors.New(errString)
 	}
+	// The expression was validated by the spdx rule.
+	r.License, _ = license.Canonicalize(r.License)
 
 	return &r, nil
 }
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+var (
+	ErrSavedSearchExists   = errors.New("a saved search with the provided name already exists")
+	ErrSavedSearchNotFound = errors.New("a saved search with the provided name does not exist")
+	ErrInvalidSearchName   = errors.New("saved search names must be 1 to 64 letters, digits, dashes or underscores")
+)
+
+// Names are used as path parameters so they are restricted to url safe characters.
+var savedSearchNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CreateSavedSearch stores a search request under the provided name.
+// The request is expected to be validated by the caller.
+func (s *Store) CreateSavedSearch(name string, req api.SearchRequest) (api.SavedSearch, error) {
+	s.searchesMu.Lock()
+	defer s.searchesMu.Unlock()
+
+	if !savedSearchNameRegexp.MatchString(name) {
+		return api.SavedSearch{}, ErrInvalidSearchName
+	}
+	if _, ok := s.savedSearches[name]; ok {
+		return api.SavedSearch{}, ErrSavedSearchExists
+	}
+
+	now := time.Now().UTC()
+	search := api.SavedSearch{
+		Name:      name,
+		Request:   req,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.savedSearches[name] = search
+	return search, nil
+}
+
+// UpdateSavedSearch replaces the search request of an existing saved search.
+func (s *Store) UpdateSavedSearch(name string, req api.SearchRequest) (api.SavedSearch, error) {
+	s.searchesMu.Lock()
+	defer s.searchesMu.Unlock()
+
+	search, ok := s.savedSearches[name]
+	if !ok {
+		return api.SavedSearch{}, ErrSavedSearchNotFound
+	}
+	search.Request = req
+	search.UpdatedAt = time.Now().UTC()
+	s.savedSearches[name] = search
+	return search, nil
+}
+
+func (s *Store) DeleteSavedSearch(name string) error {
+	s.searchesMu.Lock()
+	defer s.searchesMu.Unlock()
+
+	if _, ok := s.savedSearches[name]; !ok {
+		return ErrSavedSearchNotFound
+	}
+	delete(s.savedSearches, name)
+	return nil
+}
+
+func (s *Store) SavedSearch(name string) (api.SavedSearch, error) {
+	s.searchesMu.RLock()
+	defer s.searchesMu.RUnlock()
+
+	search, ok := s.savedSearches[name]
+	if !ok {
+		return api.SavedSearch{}, ErrSavedSearchNotFound
+	}
+	return search, nil
+}
+
+// SavedSearches returns all the saved searches sorted by name.
+func (s *Store) SavedSearches() []api.SavedSearch {
+	s.searchesMu.RLock()
+	defer s.searchesMu.RUnlock()
+
+	searches := []api.SavedSearch{}
+	for _, search := range s.savedSearches {
+		searches = append(searches, search)
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].Name < searches[j].Name
+	})
+	return searches
+}
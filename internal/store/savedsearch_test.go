@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearches(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	req := api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodAND,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchFieldLicense, Query: "GPL-3.0"}},
+	}
+
+	_, err = s.CreateSavedSearch("gpl apps", req)
+	require.Equal(t, ErrInvalidSearchName, err, "names with spaces should not be allowed")
+
+	created, err := s.CreateSavedSearch("gpl-apps", req)
+	require.NoError(t, err)
+	require.Equal(t, req, created.Request)
+
+	_, err = s.CreateSavedSearch("gpl-apps", req)
+	require.Equal(t, ErrSavedSearchExists, err, "names should be unique")
+
+	req.JoinMethod = api.SearchJoinMethodOR
+	updated, err := s.UpdateSavedSearch("gpl-apps", req)
+	require.NoError(t, err)
+	require.Equal(t, created.CreatedAt, updated.CreatedAt)
+	require.Equal(t, api.SearchJoinMethod(api.SearchJoinMethodOR), updated.Request.JoinMethod)
+
+	_, err = s.UpdateSavedSearch("mit-apps", req)
+	require.Equal(t, ErrSavedSearchNotFound, err)
+
+	_, err = s.CreateSavedSearch("apache-apps", req)
+	require.NoError(t, err)
+	searches := s.SavedSearches()
+	require.Len(t, searches, 2)
+	require.Equal(t, "apache-apps", searches[0].Name, "saved searches should be sorted by name")
+
+	require.NoError(t, s.DeleteSavedSearch("gpl-apps"))
+	_, err = s.SavedSearch("gpl-apps")
+	require.Equal(t, ErrSavedSearchNotFound, err)
+	require.Equal(t, ErrSavedSearchNotFound, s.DeleteSavedSearch("gpl-apps"))
+}
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// spdxSyntaxPattern matches the syntax of SPDX license expressions: identifiers, optionally
+// with an exception, joined by operators of any case. Balanced parentheses and known
+// identifiers are only checked by the server.
+const spdxSyntaxPattern = `^[\s(]*[A-Za-z0-9.:+-]+([\s()]+[Ww][Ii][Tt][Hh][\s()]+[A-Za-z0-9.:+-]+)?` +
+	`([\s()]+([Aa][Nn][Dd]|[Oo][Rr])[\s()]+[A-Za-z0-9.:+-]+([\s()]+[Ww][Ii][Tt][Hh][\s()]+[A-Za-z0-9.:+-]+)?)*[\s)]*$`
+
+const spdxDescription = "An SPDX license expression, e.g. MIT OR Apache-2.0. " +
+	"The identifiers must be in the SPDX license list or start with LicenseRef-."
+
+// JSONSchema returns a JSON schema document equivalent to the validation performed on new
+// records of a schema version, the current one if empty, including the registered custom
+// fields. It is derived from the validate struct tags of api.MetaRecord so both can't drift
+// apart, previous versions adapt the schema of the version they are upgraded to.
+// Validation rules that have no JSON schema equivalent are only enforced by the server.
+func (s *Store) JSONSchema(version string) (map[string]interface{}, error) {
+	if version == "" {
+		version = api.APIVersionV1
+	}
+	upgrades, err := upgradePath(version)
+	if err != nil {
+		return nil, err
+	}
+	schema := s.currentJSONSchema()
+	// Documents go through every upgrade, so each version derives its schema from the next one.
+	for i := len(upgrades) - 1; i >= 0; i-- {
+		schema = upgrades[i].jsonSchema(schema)
+	}
+	return schema, nil
+}
+
+// currentJSONSchema returns the JSON schema of documents of the current version.
+func (s *Store) currentJSONSchema() map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(api.MetaRecord{}))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "MetaRecord"
+	// Records without a slug use the slug of their title, which needs a letter or a digit.
+	schema["if"] = map[string]interface{}{
+		"not": map[string]interface{}{
+			"required":   []string{"slug"},
+			"properties": map[string]interface{}{"slug": map[string]interface{}{"minLength": 1}},
+		},
+	}
+	schema["then"] = map[string]interface{}{
+		"properties": map[string]interface{}{"title": map[string]interface{}{"pattern": `[\p{L}\p{Nd}]`}},
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, field := range urlFields {
+		if pattern := s.urlRules[field].pattern(); pattern != "" {
+			properties[string(field)].(map[string]interface{})["pattern"] = pattern
+		}
+	}
+	required, _ := schema["required"].([]string)
+	for _, name := range s.customFieldNames() {
+		field := s.customFields[name]
+		rules := splitRules(field.Validation)
+		if field.Required {
+			// Empty values are missing values for custom fields too.
+			rules = append([]string{"required"}, rules...)
+			required = append(required, name)
+		}
+		property := map[string]interface{}{"type": string(field.Type)}
+		applyRules(property, customFieldKind(field.Type), rules)
+		properties[name] = property
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func customFieldKind(t api.CustomFieldType) reflect.Kind {
+	switch t {
+	case api.CustomFieldTypeNumber:
+		return reflect.Float64
+	case api.CustomFieldTypeBoolean:
+		return reflect.Bool
+	}
+	return reflect.String
+}
+
+// structSchema returns the schema of a struct decoded from yaml. Fields that are not part
+// of the struct are allowed because they can be custom fields.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		properties[name] = typeSchema(field.Type, tag)
+		for _, rule := range splitRules(tag) {
+			if rule == "required" {
+				required = append(required, name)
+			}
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema returns the schema of a value of type t validated with the rules of tag.
+func typeSchema(t reflect.Type, tag string) map[string]interface{} {
+	rules := splitRules(tag)
+	// The rules after dive apply to the elements of slices and maps.
+	var elemRules []string
+	for i, rule := range rules {
+		if rule == "dive" {
+			rules, elemRules = rules[:i], rules[i+1:]
+			break
+		}
+	}
+
+	schema := map[string]interface{}{}
+	switch t.Kind() {
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), strings.Join(elemRules, ","))
+	case reflect.Map:
+		schema["type"] = "object"
+		// Map keys are validated with the rules between keys and endkeys.
+		if len(elemRules) > 0 && elemRules[0] == "keys" {
+			for i, rule := range elemRules {
+				if rule == "endkeys" {
+					keySchema := map[string]interface{}{}
+					applyRules(keySchema, reflect.String, elemRules[1:i])
+					schema["propertyNames"] = keySchema
+					elemRules = elemRules[i+1:]
+					break
+				}
+			}
+		}
+		schema["additionalProperties"] = typeSchema(t.Elem(), strings.Join(elemRules, ","))
+	case reflect.Struct:
+		schema = structSchema(t)
+	}
+
+	applyRules(schema, t.Kind(), rules)
+	if contains(rules, "omitempty") && t.Kind() == reflect.String && len(schema) > 1 {
+		// Empty values skip the rest of the validations.
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "maxLength": 0},
+				schema,
+			},
+		}
+	}
+	return schema
+}
+
+// applyRules adds the JSON schema keywords equivalent to validation rules to a schema.
+func applyRules(schema map[string]interface{}, kind reflect.Kind, rules []string) {
+	for _, rule := range rules {
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+		n, _ := strconv.Atoi(param)
+
+		switch name {
+		case "required":
+			// Required strings can't be empty, the presence of the field is checked
+			// by the required keyword of the parent object.
+			if kind == reflect.String {
+				schema["minLength"] = 1
+			}
+		case "url":
+			schema["format"] = "uri"
+		case "email":
+			schema["format"] = "email"
+		case "spdx":
+			schema["pattern"] = spdxSyntaxPattern
+			schema["description"] = spdxDescription
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "gt", "gte", "min", "lt", "lte", "max", "len":
+			applyBound(schema, kind, name, n)
+		default:
+			if pattern, ok := patternValidations[name]; ok {
+				schema["pattern"] = pattern.String()
+			}
+		}
+	}
+}
+
+// applyBound translates the comparison rules, which compare lengths for strings, slices
+// and maps and the value itself for numbers.
+func applyBound(schema map[string]interface{}, kind reflect.Kind, rule string, n int) {
+	var minKeyword, maxKeyword string
+	switch kind {
+	case reflect.String:
+		minKeyword, maxKeyword = "minLength", "maxLength"
+	case reflect.Slice:
+		minKeyword, maxKeyword = "minItems", "maxItems"
+	case reflect.Map:
+		minKeyword, maxKeyword = "minProperties", "maxProperties"
+	default:
+		switch rule {
+		case "gt":
+			schema["exclusiveMinimum"] = n
+		case "gte", "min":
+			schema["minimum"] = n
+		case "lt":
+			schema["exclusiveMaximum"] = n
+		case "lte", "max":
+			schema["maximum"] = n
+		case "len":
+			schema["enum"] = []int{n}
+		}
+		return
+	}
+
+	switch rule {
+	case "gt":
+		schema[minKeyword] = n + 1
+	case "gte", "min":
+		schema[minKeyword] = n
+	case "lt":
+		schema[maxKeyword] = n - 1
+	case "lte", "max":
+		schema[maxKeyword] = n
+	case "len":
+		schema[minKeyword] = n
+		schema[maxKeyword] = n
+	}
+}
+
+func splitRules(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
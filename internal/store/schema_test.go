@@ -0,0 +1,144 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaAgrees validates a file both with newRecord and the JSON schema of its apiVersion
+// and ensures both reach the same verdict.
+func schemaAgrees(t *testing.T, s *Store, fp string) {
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+	schemaAgreesData(t, s, fp, data)
+}
+
+// schemaAgreesData is schemaAgrees for documents that are not stored in a file.
+func schemaAgreesData(t *testing.T, s *Store, fp string, data []byte) {
+	_, recordErr := s.newRecord(data)
+	// Unsupported versions are rejected by the schema of the current one.
+	version, _ := documentAPIVersion(data)
+	if _, err := s.JSONSchema(version); err != nil {
+		version = api.APIVersionV1
+	}
+	schema := compileSchema(t, s, version)
+
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		require.Equal(t, ErrUnparsable, recordErr, "%s: unparsable files should be rejected", fp)
+		return
+	}
+	// Tools validate the json equivalent of the yaml document.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		require.Error(t, recordErr, fp)
+		return
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(raw))
+	require.NoError(t, err, fp)
+	require.Equal(t, recordErr == nil, result.Valid(), "%s: newRecord error: %v, schema errors: %v",
+		fp, recordErr, result.Errors())
+}
+
+func TestJSONSchema(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "supportTier", Type: api.CustomFieldTypeString, Validation: "oneof=gold silver"})
+	require.NoError(t, err)
+
+	dirs := []string{validDir, invalidDir, invalidSchemaDir, legacyDir, "../server/testdata/valid", "../server/testdata/invalid"}
+	for _, dir := range dirs {
+		fis, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, fi := range fis {
+			schemaAgrees(t, s, filepath.Join(dir, fi.Name()))
+		}
+	}
+}
+
+func TestJSONSchemaCustomFields(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "owner", Type: api.CustomFieldTypeString, Required: true})
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "supportTier", Type: api.CustomFieldTypeString, Required: true, Validation: "oneof=gold silver"})
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "replicas", Type: api.CustomFieldTypeNumber, Validation: "min=1"})
+	require.NoError(t, err)
+	documents := map[string]fields{
+		"complete":          {"owner": "platform", "supportTier": "gold", "replicas": 2},
+		"missing optional":  {"owner": "platform", "supportTier": "gold"},
+		"missing required":  {"supportTier": "gold"},
+		"empty required":    {"owner": "", "supportTier": "gold"},
+		"null required":     {"owner": nil, "supportTier": "gold"},
+		"empty with rules":  {"owner": "platform", "supportTier": ""},
+		"unsatisfied rules": {"owner": "platform", "supportTier": "bronze"},
+		"wrong type":        {"owner": "platform", "supportTier": "gold", "replicas": "two"},
+	}
+	for name, custom := range documents {
+		schemaAgreesData(t, s, name, testRecord(t, custom))
+	}
+}
+
+func TestJSONSchemaRules(t *testing.T) {
+	// Without a natural key every case can be appended to the same store.
+	s, err := New(WithNaturalKey(), WithURLRule(api.SearchFieldSource, URLRule{
+		Schemes: []string{"https", "git"},
+		Hosts:   []string{"github.com", "*.gitlab.com"},
+	}))
+	require.NoError(t, err)
+	schema := compileSchema(t, s, "")
+
+	tests := []struct {
+		name   string
+		fields fields
+		valid  bool
+	}{
+		{name: "valid", fields: fields{}, valid: true},
+		{name: "website scheme case", fields: fields{"website": "HTTPS://website.com"}, valid: true},
+		{name: "website scheme", fields: fields{"website": "ftp://x"}},
+		{name: "source host", fields: fields{"source": "https://bitbucket.org/random/repo"}},
+		{name: "source host case", fields: fields{"source": "git://GitHub.com/random/repo"}, valid: true},
+		{name: "source subdomain", fields: fields{"source": "https://code.gitlab.com/random/repo"}, valid: true},
+		{name: "source subdomain root", fields: fields{"source": "https://evilgitlab.com/random/repo"}},
+		{name: "source host prefix", fields: fields{"source": "https://github.com.evil.io/repo"}},
+		{name: "source user info", fields: fields{"source": "https://github.com@evil.io/repo"}},
+		{name: "source port", fields: fields{"source": "https://github.com:443/random/repo"}, valid: true},
+		{name: "source scheme", fields: fields{"source": "http://github.com/random/repo"}},
+		{name: "license expression", fields: fields{"license": "(GPL-2.0-or-later with Classpath-exception-2.0) or MIT"}, valid: true},
+		{name: "license missing operator", fields: fields{"license": "Apache 2"}},
+		{name: "license dangling operator", fields: fields{"license": "MIT OR"}},
+		{name: "license leading operator", fields: fields{"license": "AND MIT"}},
+	}
+	for _, test := range tests {
+		data := testRecord(t, test.fields)
+		_, err := s.Append(data, WriteOptions{})
+		require.Equal(t, test.valid, err == nil, "%s: append error: %v", test.name, err)
+
+		var doc interface{}
+		require.NoError(t, yaml.Unmarshal(data, &doc))
+		raw, err := json.Marshal(doc)
+		require.NoError(t, err)
+		result, err := schema.Validate(gojsonschema.NewBytesLoader(raw))
+		require.NoError(t, err)
+		require.Equal(t, test.valid, result.Valid(), "%s: schema errors: %v", test.name, result.Errors())
+	}
+}
+
+func compileSchema(t *testing.T, s *Store, version string) *gojsonschema.Schema {
+	t.Helper()
+	document, err := s.JSONSchema(version)
+	require.NoError(t, err)
+	raw, err := json.Marshal(document)
+	require.NoError(t, err)
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(raw))
+	require.NoError(t, err, "the generated schema should be a valid JSON schema")
+	return schema
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+// SearchOptions modify the behavior of a search.
+type SearchOptions struct {
+	// Explain makes the search return a breakdown of how every term was resolved.
+	Explain bool
+	// Strict makes the search fail if any of the terms can't be resolved by its index.
+	// Otherwise the term is skipped and reported as a warning.
+	Strict bool
+	// LatestOnly collapses the matches of every application to its highest version,
+	// after joining the terms.
+	LatestOnly bool
+}
+
+// SearchResult holds the records that matched a search and, if requested, its explanation.
+type SearchResult struct {
+	Records     []*api.MetaRecord
+	Explanation *api.SearchExplanation
+	// Warnings lists the terms that were skipped because they couldn't be resolved.
+	Warnings []api.SearchWarning
+}
+
+// TermError is returned by strict searches when a search term can't be resolved.
+type TermError struct {
+	// Position of the term in the search request.
+	Index int
+	Term  api.SearchTerm
+	Err   error
+}
+
+func (e *TermError) Error() string {
+	return fmt.Sprintf("search term %d (field: %s, query: %q) could not be resolved: %s",
+		e.Index, e.Term.Field, e.Term.Query, e.Err)
+}
+
+func (e *TermError) Unwrap() error {
+	return e.Err
+}
+
+// termResult holds the outcome of querying an index for a single search term.
+type termResult struct {
+	records []*api.MetaRecord
+	err     error
+}
+
+// OptionsFromRequest returns the search options requested by a search request.
+func OptionsFromRequest(req api.SearchRequest) SearchOptions {
+	return SearchOptions{
+		Explain:    req.Explain,
+		Strict:     req.Mode == api.SearchModeStrict,
+		LatestOnly: req.LatestOnly,
+	}
+}
+
+func (s *Store) Search(joinMethod api.SearchJoinMethod,
+	terms []api.SearchTerm, opts SearchOptions) (*SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.search(joinMethod, terms, opts)
+}
+
+// search is the implementation of Search, the caller must hold the store lock.
+func (s *Store) search(joinMethod api.SearchJoinMethod,
+	terms []api.SearchTerm, opts SearchOptions) (*SearchResult, error) {
+	if len(terms) == 0 {
+		return nil, errors.New("at least one search term must be provided")
+	}
+
+	// Every goroutine writes to its own slot so no further synchronization is needed
+	// and the results can be joined in the same order the terms were provided.
+	termResults := make([]termResult, len(terms))
+	wg := sync.WaitGroup{}
+	wg.Add(len(terms))
+
+	for i, term := range terms {
+		go func(i int, term api.SearchTerm) {
+			defer wg.Done()
+			index, err := s.termIndex(term)
+			if err != nil {
+				termResults[i].err = err
+				return
+			}
+			termResults[i].records, termResults[i].err = index.Search(term.Query)
+		}(i, term)
+	}
+	wg.Wait()
+
+	if opts.Strict {
+		for i, result := range termResults {
+			if result.err != nil {
+				return nil, &TermError{Index: i, Term: terms[i], Err: result.err}
+			}
+		}
+	}
+
+	var explanation *api.SearchExplanation
+	if opts.Explain {
+		explanation = &api.SearchExplanation{
+			JoinMethod: joinMethod,
+			Terms:      []api.TermExplanation{},
+		}
+	}
+
+	// Used to keep track of how many terms have matched a record.
+	foundRecordsCounts := map[*api.MetaRecord]int{}
+	// Records in the order they were first found.
+	candidates := []*api.MetaRecord{}
+	// Number of terms that were successfully resolved, skipped terms must not be
+	// taken into account by AND joins or no record would ever match all the terms.
+	joinedTerms := 0
+	warnings := []api.SearchWarning{}
+	// Size of the result set after joining every term, only tracked for explanations.
+	remaining := 0
+
+	for i, result := range termResults {
+		// A record could be returned more than once by the same index (e.g. two maintainers
+		// with the same name) and it should only be counted once per term.
+		termMatches := map[*api.MetaRecord]bool{}
+		if result.err != nil {
+			warnings = append(warnings, api.SearchWarning{
+				Term:    i,
+				Field:   terms[i].Field,
+				Query:   terms[i].Query,
+				Message: result.err.Error(),
+			})
+		} else {
+			joinedTerms += 1
+			for _, match := range result.records {
+				if termMatches[match] {
+					continue
+				}
+				termMatches[match] = true
+				if foundRecordsCounts[match] == 0 {
+					candidates = append(candidates, match)
+				}
+				foundRecordsCounts[match] += 1
+			}
+		}
+
+		if explanation == nil {
+			continue
+		}
+
+		switch joinMethod {
+		case api.SearchJoinMethodOR:
+			remaining = len(candidates)
+		case api.SearchJoinMethodAND:
+			remaining = 0
+			for _, count := range foundRecordsCounts {
+				if count == joinedTerms {
+					remaining += 1
+				}
+			}
+		}
+		// The error was already recorded while resolving the term.
+		index, _ := s.termIndex(terms[i])
+		explanation.Terms = append(explanation.Terms, explainTerm(index,
+			terms[i], len(termMatches), result.err, remaining))
+	}
+
+	records := []*api.MetaRecord{}
+	for _, candidate := range candidates {
+		switch joinMethod {
+		// Every record that was matched at least once is part of the results.
+		case api.SearchJoinMethodOR:
+			records = append(records, candidate)
+		// Only add the record to the results if its been matched in all term searches.
+		case api.SearchJoinMethodAND:
+			if foundRecordsCounts[candidate] == joinedTerms {
+				records = append(records, candidate)
+			}
+		}
+	}
+
+	if opts.LatestOnly {
+		records = latestVersions(records)
+	}
+
+	return &SearchResult{Records: records, Explanation: explanation, Warnings: warnings}, nil
+}
+
+// explainTerm builds the explanation for a single search term.
+// index can be nil if the field is not indexed.
+func explainTerm(index storeIndex, term api.SearchTerm, hits int, err error,
+	remaining int) api.TermExplanation {
+	explanation := api.TermExplanation{
+		Field:           term.Field,
+		Query:           term.Query,
+		NormalizedQuery: term.Query,
+		Hits:            hits,
+		Remaining:       remaining,
+	}
+	if index != nil {
+		explanation.Index = index.Kind()
+		explanation.NormalizedQuery = index.NormalizeQuery(term.Query)
+	}
+	if err != nil {
+		explanation.Error = err.Error()
+	}
+	return explanation
+}
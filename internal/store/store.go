@@ -2,152 +2,415 @@ package store
 
 import (
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/common"
 )
 
 // TODO: create an index to have fast search for fields.
 
-var ErrUnparsable = errors.New("could not parse input into a record")
+var (
+	ErrUnparsable         = errors.New("could not parse input into a record")
+	ErrRecordNotFound     = errors.New("a record with the provided id does not exist")
+	ErrPreconditionFailed = errors.New("the record has been modified since it was last retrieved")
+)
 
 type Store struct {
 	// Use a read/write mutex to allow performant concurrent reads.
 	mu      sync.RWMutex
 	indexes map[api.SearchField]storeIndex
+	records map[string]*api.MetaRecord
+	// history holds every revision of every record, including deleted ones.
+	history map[string][]api.Revision
+	// changes is the log of every mutation of the records.
+	changes *eventLog
+
+	// Saved searches don't interact with the indexes so they have their own lock.
+	searchesMu    sync.RWMutex
+	savedSearches map[string]api.SavedSearch
+
+	subscriptions subscriptions
+
+	// naturalKey holds the fields that identify a record and keys maps the natural key
+	// of every record to its id.
+	naturalKey []api.SearchField
+	keys       map[string]string
+	// apps maps the slug of every application to the ids of its versions.
+	apps map[string]map[string]struct{}
+	// customFields are the fields added to the records schema at runtime.
+	customFields map[string]api.CustomField
+	policy       licensePolicy
+	urlRules     map[api.SearchField]URLRule
+	// analyzers holds the analyzers of the full text fields that don't use the standard one.
+	analyzers     map[api.SearchField]string
+	stripMarkdown bool
 }
 
-func New() (*Store, error) {
+func New(opts ...Option) (*Store, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := validateNaturalKey(o.naturalKey); err != nil {
+		return nil, err
+	}
+	if err := validateURLRules(o.urlRules); err != nil {
+		return nil, err
+	}
+	if err := validateIndexStrategies(o.strategies); err != nil {
+		return nil, err
+	}
+	if err := validateAnalyzers(o.analyzers, o.strategies); err != nil {
+		return nil, err
+	}
+	if o.changeLogCapacity < 1 {
+		return nil, ErrInvalidChangeLogCapacity
+	}
+	policy, err := newLicensePolicy(o.licensePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		indexes:       map[api.SearchField]storeIndex{},
+		records:       map[string]*api.MetaRecord{},
+		history:       map[string][]api.Revision{},
+		changes:       newEventLog(o.changeLogCapacity),
+		savedSearches: map[string]api.SavedSearch{},
+		subscriptions: subscriptions{byID: map[string]*subscription{}},
+		naturalKey:    o.naturalKey,
+		keys:          map[string]string{},
+		apps:          map[string]map[string]struct{}{},
+		customFields:  map[string]api.CustomField{},
+		policy:        policy,
+		urlRules:      o.urlRules,
+		analyzers:     o.analyzers,
+		stripMarkdown: o.stripMarkdown,
+	}
+
 	// Create indexes for every possible search field.
-	indexes := map[api.SearchField]storeIndex{}
 	for _, searchField := range api.ValidSearchFieldValues() {
-		isFullText := false
-		if searchField == api.SearchFieldDescription {
-			isFullText = true
+		if searchField == api.SearchFieldLabels {
+			s.indexes[searchField] = newLabelIndex(s.allRecords)
+			continue
 		}
-		index, err := newIndex(isFullText)
+		if searchField == api.SearchFieldMaintainer {
+			s.indexes[searchField] = newMaintainerIndex()
+			continue
+		}
+		if searchField == api.SearchFieldSourceHost || searchField == api.SearchFieldSourceOwner ||
+			searchField == api.SearchFieldMaintainerEmail {
+			s.indexes[searchField] = newCaseInsensitiveIndex()
+			continue
+		}
+		if searchField == api.SearchFieldLicense {
+			s.indexes[searchField] = newLicenseIndex()
+			continue
+		}
+		index, err := s.indexFor(searchField, strategyOf(searchField, o.strategies))
 		// If any of the indexes failes to be initialized the store won't work
 		// correctly and thus we should abort the whole operation.
 		if err != nil {
 			return nil, err
 		}
-		indexes[searchField] = index
+		s.indexes[searchField] = index
+	}
+
+	return s, nil
+}
+
+// allRecords returns every record in the store, the caller must hold the store lock.
+func (s *Store) allRecords() []*api.MetaRecord {
+	records := make([]*api.MetaRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
 	}
+	return records
+}
+
+// WriteOptions hold the metadata of a mutation.
+type WriteOptions struct {
+	// Author is recorded in the history of the record.
+	Author string
+	// IfMatch is called with the current revision of the record before updating or
+	// deleting it, the operation fails with ErrPreconditionFailed if it returns false.
+	// It is called while holding the store write lock so no other write can happen
+	// between the check and the mutation. A nil function skips the check.
+	IfMatch func(revision int) bool
+}
 
-	return &Store{indexes: indexes}, nil
+// Append parses, validates and indexes a new record.
+// Returns the stored record, which has its ID assigned. Records with the same natural key
+// as an existing one fail with a DuplicateError.
+func (s *Store) Append(rawRecord []byte, opts WriteOptions) (*api.MetaRecord, error) {
+	record, _, err := s.append(rawRecord, opts, false)
+	return record, err
 }
 
-func (s *Store) Append(rawRecord []byte) error {
+// Upsert adds a record or replaces the one with the same natural key, if any.
+// Reports whether an existing record was replaced.
+func (s *Store) Upsert(rawRecord []byte, opts WriteOptions) (*api.MetaRecord, bool, error) {
+	return s.append(rawRecord, opts, true)
+}
+
+// append is the implementation of Append and Upsert, which replaces the record with the
+// same natural key.
+func (s *Store) append(rawRecord []byte, opts WriteOptions, upsert bool) (*api.MetaRecord, bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	record, err := newRecord(rawRecord)
+	record, err := s.newRecord(rawRecord)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key, hasKey := s.naturalKeyOf(record)
+	if id, exists := s.keys[key]; hasKey && exists {
+		if !upsert {
+			return nil, false, &DuplicateError{ID: id}
+		}
+		record, err := s.replace(s.records[id], record, opts)
+		return record, err == nil, err
+	}
+
+	record.ID, err = common.NewID()
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.indexRecord(record)
+	if err != nil {
+		return nil, false, err
+	}
+	s.records[record.ID] = record
+	if hasKey {
+		s.keys[key] = record.ID
+	}
+	s.addToApp(record)
+
+	s.recordChange(api.ChangeOpCreate, record.ID, record, opts.Author)
+	s.notifySubscriptions(record)
+
+	return record, false, nil
+}
+
+// Get returns the record with the provided id.
+func (s *Store) Get(id string) (*api.MetaRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return record, nil
+}
+
+// Update replaces the record with the provided id with a new version parsed from rawRecord.
+// Returns the new version of the record.
+func (s *Store) Update(id string, rawRecord []byte, opts WriteOptions) (*api.MetaRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.records[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	record, err := s.newRecord(rawRecord)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := s.naturalKeyOf(record); ok {
+		if existing, exists := s.keys[key]; exists && existing != id {
+			return nil, &DuplicateError{ID: existing}
+		}
+	}
+	return s.replace(old, record, opts)
+}
+
+// replace swaps a stored record for a new version of it, the caller must hold the write lock.
+func (s *Store) replace(old *api.MetaRecord, record *api.MetaRecord, opts WriteOptions) (*api.MetaRecord, error) {
+	if opts.IfMatch != nil && !opts.IfMatch(old.Revision) {
+		return nil, ErrPreconditionFailed
+	}
+	id := old.ID
+	record.ID = id
+
+	// Records are never modified in place because previous search results could still
+	// be referencing them.
+	err := s.unindexRecord(old)
+	if err != nil {
+		return nil, err
+	}
+	err = s.indexRecord(record)
+	if err != nil {
+		// Restore the previous version so the record doesn't disappear from searches.
+		if restoreErr := s.indexRecord(old); restoreErr != nil {
+			return nil, restoreErr
+		}
+		return nil, err
+	}
+	s.records[id] = record
+	if key, ok := s.naturalKeyOf(old); ok {
+		delete(s.keys, key)
+	}
+	if key, ok := s.naturalKeyOf(record); ok {
+		s.keys[key] = id
+	}
+	s.removeFromApp(old)
+	s.addToApp(record)
+
+	s.recordChange(api.ChangeOpUpdate, id, record, opts.Author)
+	s.notifySubscriptions(record)
+
+	return record, nil
+}
+
+// Delete removes the record with the provided id from the store.
+func (s *Store) Delete(id string, opts WriteOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	if opts.IfMatch != nil && !opts.IfMatch(record.Revision) {
+		return ErrPreconditionFailed
+	}
+
+	err := s.unindexRecord(record)
 	if err != nil {
 		return err
 	}
+	delete(s.records, id)
+	if key, ok := s.naturalKeyOf(record); ok {
+		delete(s.keys, key)
+	}
+	s.removeFromApp(record)
+
+	s.recordChange(api.ChangeOpDelete, id, nil, opts.Author)
+
+	return nil
+}
+
+// indexEntry is a single value a record is indexed with.
+type indexEntry struct {
+	field api.SearchField
+	value string
+}
 
+// newRecord parses a record and validates it against the fixed schema and the registered
+// custom fields, the caller must hold the store lock.
+func (s *Store) newRecord(rawRecord []byte) (*api.MetaRecord, error) {
+	record, err := newRecord(rawRecord)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateCustomFields(record); err != nil {
+		return nil, err
+	}
+	if err := s.checkURLRules(record); err != nil {
+		return nil, err
+	}
+	if err := s.checkLicensePolicy(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// fieldValues returns the values of a search field of a record, including the fields that
+// are derived by the store. The caller must hold the store lock.
+func (s *Store) fieldValues(record *api.MetaRecord, field api.SearchField) ([]string, error) {
+	switch field {
+	case api.SearchFieldLicense:
+		return licenseValues(record), nil
+	case api.SearchFieldSourceHost, api.SearchFieldSourceOwner:
+		return sourceValues(record, field), nil
+	case api.SearchFieldCompliance:
+		status, _ := s.policy.evaluate(record.License)
+		return []string{string(status)}, nil
+	}
+	return record.FieldValues(field)
+}
+
+// indexEntries returns all the values a record must be indexed with.
+// The caller must hold the store lock.
+func (s *Store) indexEntries(record *api.MetaRecord) ([]indexEntry, error) {
+	entries := []indexEntry{}
 	for _, field := range api.ValidSearchFieldValues() {
-		if field == api.SearchFieldMaintainerEmail ||
-			field == api.SearchFieldMaintainerName {
-			continue
-		}
-		fieldValue, err := record.FieldValueFromSearchField(field)
-		// This should not happend because we're skipping the invalid fields
-		// but I'm leaving it as a safeguard.
+		values, err := s.fieldValues(record, field)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		// If we fail to add the record to any index searches won't work correclty so abort the whole operation.
-		err = s.indexes[field].Index(record, fieldValue)
-		if err != nil {
-			return err
+		for _, value := range values {
+			// Derived fields can be missing, e.g. the owner of a source outside the known forges.
+			if value != "" {
+				entries = append(entries, indexEntry{field: field, value: value})
+			}
 		}
 	}
+	return append(entries, s.customEntries(record)...), nil
+}
+
+// indexRecord adds a record to all the indexes. The caller must hold the store write lock.
+// If we fail to add the record to any index searches won't work correctly so the entries that
+// were already added are removed and the whole operation is aborted.
+func (s *Store) indexRecord(record *api.MetaRecord) error {
+	entries, err := s.indexEntries(record)
+	if err != nil {
+		return err
+	}
 
-	// Since maintainers is a list it needs a separate implementation.
-	for _, maintainer := range record.Maintainers {
-		// If we fail to add the record to the maintainer fields indexes searches won't work correctly so we should abort.
-		err := s.indexes[api.SearchFieldMaintainerEmail].Index(record, maintainer.Email)
+	for i, entry := range entries {
+		err := s.indexes[entry.field].Index(record, entry.value)
 		if err != nil {
+			for _, indexed := range entries[:i] {
+				// Nothing else can be done if the rollback fails.
+				_ = s.indexes[indexed.field].Remove(record, indexed.value)
+			}
 			return err
 		}
-		err = s.indexes[api.SearchFieldMaintainerName].Index(record, maintainer.Name)
+	}
+	return nil
+}
+
+// unindexRecord removes a record from all the indexes. The caller must hold the store write lock.
+func (s *Store) unindexRecord(record *api.MetaRecord) error {
+	entries, err := s.indexEntries(record)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err := s.indexes[entry.field].Remove(record, entry.value)
 		if err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
-func (s *Store) Search(joinMethod api.SearchJoinMethod,
-	terms []api.SearchTerm) ([]*api.MetaRecord, error) {
+// FieldValues returns the distinct values indexed for a search field sorted by value.
+func (s *Store) FieldValues(field api.SearchField) ([]api.FieldValue, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(terms) == 0 {
-		return nil, errors.New("at least one search term must be provided")
+	index, ok := s.indexes[field]
+	if !ok {
+		return nil, errors.New("the provided field is not indexed")
 	}
 
-	// Used to keep track of how many times a record has shown up in term searches.
-	foundRecordsCounts := map[*api.MetaRecord]int{}
-	results := []*api.MetaRecord{}
-
-	// Results returned from individual index searches.
-	indexesQueryResults := make(chan []*api.MetaRecord)
-
-	// Goroutines sync.
-	done := make(chan bool)
-	wg := sync.WaitGroup{}
-	wg.Add(len(terms))
-
-	for _, term := range terms {
-		go func(term api.SearchTerm) {
-			index := s.indexes[term.Field]
-			indexQueryResult, err := index.Search(term.Query)
-			// NOTE: We could argue on whether an error from a single index should fail the entire operation.
-			// For the purposes of this challenge I'll allow the operation to proceed just in aces other
-			// indexes are able to return valid results.
-			if err == nil {
-				indexesQueryResults <- indexQueryResult
-			}
-			wg.Done()
-		}(term)
-	}
-
-	go func() {
-		wg.Wait()
-		done <- true
-	}()
-
-	// Process all the results coming from the indexes queries and cleanup when they're all done.
-P:
-	for {
-		select {
-		// Clean channels and finish the loop if there's no more index requests to process.
-		case <-done:
-			close(done)
-			close(indexesQueryResults)
-			break P
-		case indexQueryResult := <-indexesQueryResults:
-			for _, match := range indexQueryResult {
-				switch joinMethod {
-				// Only add the record to the results on its first match.
-				case api.SearchJoinMethodOR:
-					if foundRecordsCounts[match] == 0 {
-						foundRecordsCounts[match] = 1
-						results = append(results, match)
-					}
-				// Only add the record to the results if its been matched in all term searches.
-				case api.SearchJoinMethodAND:
-					foundRecordsCounts[match] += 1
-					if foundRecordsCounts[match] == len(terms) {
-						results = append(results, match)
-					}
-				}
-			}
-		}
+	values := []api.FieldValue{}
+	for value, count := range index.Values() {
+		values = append(values, api.FieldValue{Value: value, Count: count})
 	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Value < values[j].Value
+	})
 
-	return results, nil
+	return values, nil
 }
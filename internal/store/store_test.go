@@ -0,0 +1,146 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore creates a store populated with all the records in the valid testdata directory.
+func newTestStore(t *testing.T, opts ...Option) *Store {
+	s, err := New(opts...)
+	require.NoError(t, err)
+
+	fis, err := os.ReadDir(validDir)
+	require.NoError(t, err, "Test dir for valid records doesn't exist")
+	for _, fi := range fis {
+		data, err := os.ReadFile(filepath.Join(validDir, fi.Name()))
+		require.NoError(t, err)
+		_, err = s.Append(data, WriteOptions{})
+		require.NoError(t, err, "Valid files should be appended correctly")
+	}
+	return s
+}
+
+// fields are the top level fields of a test record that differ from valid1.yaml.
+type fields map[string]interface{}
+
+// testRecord returns the valid1.yaml test record with the given fields replaced, fields
+// that valid1.yaml doesn't have are added in name order.
+func testRecord(t *testing.T, overrides fields) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(validDir, "valid1.yaml"))
+	require.NoError(t, err)
+	var record yaml.MapSlice
+	require.NoError(t, yaml.Unmarshal(data, &record))
+
+	seen := map[string]bool{}
+	for i, item := range record {
+		name := item.Key.(string)
+		if value, ok := overrides[name]; ok {
+			record[i].Value = value
+		}
+		seen[name] = true
+	}
+	added := []string{}
+	for name := range overrides {
+		if !seen[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		record = append(record, yaml.MapItem{Key: name, Value: overrides[name]})
+	}
+
+	// JSON documents are valid yaml and, unlike the yaml encoding, quote blank strings.
+	raw, err := yaml.MarshalWithOptions(record, yaml.JSON())
+	require.NoError(t, err)
+	return raw
+}
+
+func TestFieldValues(t *testing.T) {
+	s := newTestStore(t)
+
+	values, err := s.FieldValues(api.SearchFieldCompany)
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldValue{
+		{Value: "Random Inc.", Count: 1},
+		{Value: "Upbound Inc.", Count: 1},
+	}, values, "values should be distinct and sorted by value")
+
+	values, err = s.FieldValues(api.SearchFieldLicense)
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldValue{{Value: "Apache-2.0", Count: 2}}, values)
+
+	_, err = s.FieldValues("unknown")
+	require.Error(t, err, "fields without an index should not be listable")
+}
+
+func TestSearchExplain(t *testing.T) {
+	s := newTestStore(t)
+
+	terms := []api.SearchTerm{
+		{Field: api.SearchFieldLicense, Query: "Apache-2.0"},
+		{Field: api.SearchFieldCompany, Query: "Upbound Inc."},
+		{Field: api.SearchFieldDescription, Query: "Interesting"},
+		{Field: api.SearchFieldTitle, Query: ""},
+	}
+
+	result, err := s.Search(api.SearchJoinMethodAND, terms, SearchOptions{})
+	require.NoError(t, err)
+	require.Nil(t, result.Explanation, "explanations should only be built when requested")
+
+	result, err = s.Search(api.SearchJoinMethodAND, terms, SearchOptions{Explain: true})
+	require.NoError(t, err)
+	require.Empty(t, result.Records)
+	require.Equal(t, &api.SearchExplanation{
+		JoinMethod: api.SearchJoinMethodAND,
+		Terms: []api.TermExplanation{
+			{Field: api.SearchFieldLicense, Query: "Apache-2.0", Index: indexKindExact,
+				NormalizedQuery: "Apache-2.0", Hits: 2, Remaining: 2},
+			{Field: api.SearchFieldCompany, Query: "Upbound Inc.", Index: indexKindExact,
+				NormalizedQuery: "Upbound Inc.", Hits: 1, Remaining: 1},
+			{Field: api.SearchFieldDescription, Query: "Interesting", Index: indexKindFullText,
+				NormalizedQuery: "interesting", Hits: 1, Remaining: 0},
+			{Field: api.SearchFieldTitle, Query: "", Index: indexKindExact,
+				NormalizedQuery: "", Hits: 0, Remaining: 0, Error: "must provide a valid search term"},
+		},
+	}, result.Explanation)
+
+	result, err = s.Search(api.SearchJoinMethodOR, terms[1:3], SearchOptions{Explain: true})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+	require.Equal(t, 1, result.Explanation.Terms[0].Remaining)
+	require.Equal(t, 2, result.Explanation.Terms[1].Remaining, "OR joins should grow the result set")
+}
+
+func TestSearchSkippedTerms(t *testing.T) {
+	s := newTestStore(t)
+
+	terms := []api.SearchTerm{
+		{Field: api.SearchFieldCompany, Query: "Upbound Inc."},
+		{Field: api.SearchFieldTitle, Query: ""},
+	}
+
+	result, err := s.Search(api.SearchJoinMethodAND, terms, SearchOptions{})
+	require.NoError(t, err, "lenient searches should not fail if a term can't be resolved")
+	require.Len(t, result.Records, 1, "skipped terms should not be taken into account by AND joins")
+	require.Equal(t, []api.SearchWarning{{
+		Term:    1,
+		Field:   api.SearchFieldTitle,
+		Query:   "",
+		Message: "must provide a valid search term",
+	}}, result.Warnings)
+
+	_, err = s.Search(api.SearchJoinMethodAND, terms, SearchOptions{Strict: true})
+	var termErr *TermError
+	require.ErrorAs(t, err, &termErr, "strict searches should fail if a term can't be resolved")
+	require.Equal(t, 1, termErr.Index)
+	require.Equal(t, terms[1], termErr.Term)
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+)
+
+// IndexStrategy determines how the values of a field are indexed.
+type IndexStrategy string
+
+const (
+	// IndexExact only matches whole values.
+	IndexExact IndexStrategy = "exact"
+	// IndexFullText matches the words of the values, analyzed with the analyzer of the field.
+	IndexFullText IndexStrategy = "fullText"
+	// IndexBoth keeps an exact and a full text index, search terms choose which one is used
+	// with their match mode and use the exact one by default.
+	IndexBoth IndexStrategy = "both"
+)
+
+var ErrInvalidIndexStrategy = errors.New("the index strategy is invalid")
+
+// strategyFields are the built in fields whose index strategy can be configured, the rest
+// have specialized indexes that other features rely on.
+var strategyFields = map[api.SearchField]bool{
+	api.SearchFieldTitle:          true,
+	api.SearchFieldVersion:        true,
+	api.SearchFieldMaintainerName: true,
+	api.SearchFieldCompany:        true,
+	api.SearchFieldWebsite:        true,
+	api.SearchFieldSource:         true,
+	api.SearchFieldDescription:    true,
+}
+
+// IsValid determines if the instance of IndexStrategy is one of the valid enum values.
+func (s IndexStrategy) IsValid() error {
+	switch s {
+	case IndexExact, IndexFullText, IndexBoth:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidIndexStrategy, s)
+}
+
+func (s IndexStrategy) hasFullText() bool {
+	return s == IndexFullText || s == IndexBoth
+}
+
+func validateIndexStrategies(strategies map[api.SearchField]IndexStrategy) error {
+	for field, strategy := range strategies {
+		if err := strategy.IsValid(); err != nil {
+			return err
+		}
+		if !strategyFields[field] {
+			return fmt.Errorf("%w: the index of %s can't be configured", ErrInvalidIndexStrategy, field)
+		}
+	}
+	return nil
+}
+
+// strategyOf returns the index strategy of a built in field, only descriptions are full
+// text by default.
+func strategyOf(field api.SearchField, strategies map[api.SearchField]IndexStrategy) IndexStrategy {
+	if strategy, ok := strategies[field]; ok {
+		return strategy
+	}
+	if field == api.SearchFieldDescription {
+		return IndexFullText
+	}
+	return IndexExact
+}
+
+// indexFor creates the index of a field following a strategy, the caller must hold the store lock.
+func (s *Store) indexFor(field api.SearchField, strategy IndexStrategy) (storeIndex, error) {
+	switch strategy {
+	case IndexFullText:
+		return s.fullTextIndexFor(field)
+	case IndexBoth:
+		fullText, err := s.fullTextIndexFor(field)
+		if err != nil {
+			return nil, err
+		}
+		exact := exactMatchSearchIndex{mapping: map[string][]*api.MetaRecord{}}
+		return &dualIndex{exactMatchSearchIndex: exact, fullText: fullText}, nil
+	}
+	return newIndex(false)
+}
+
+// termIndex returns the index that resolves a search term according to its match mode,
+// the caller must hold the store lock.
+func (s *Store) termIndex(term api.SearchTerm) (storeIndex, error) {
+	index, ok := s.indexes[term.Field]
+	if !ok {
+		return nil, errors.New("the provided field is not indexed")
+	}
+	if dual, ok := index.(*dualIndex); ok && term.Match == api.MatchModeFullText {
+		return dual.fullText, nil
+	}
+	if term.Match != "" && index.Kind() != string(term.Match) {
+		return nil, fmt.Errorf("the field %s does not support %s matches", term.Field, term.Match)
+	}
+	return index, nil
+}
+
+// fullTextOf returns the full text index of a field indexed with the full text or both strategies.
+func fullTextOf(index storeIndex) (*fullTextSearchIndex, bool) {
+	switch i := index.(type) {
+	case *fullTextSearchIndex:
+		return i, true
+	case *dualIndex:
+		return i.fullText, true
+	}
+	return nil, false
+}
+
+// dualIndex indexes the values of a field both exactly and by words. It behaves like the
+// exact index, the full text index is only used by terms that select it.
+type dualIndex struct {
+	exactMatchSearchIndex
+	fullText *fullTextSearchIndex
+}
+
+func (i *dualIndex) Index(record *api.MetaRecord, data string) error {
+	if err := i.exactMatchSearchIndex.Index(record, data); err != nil {
+		return err
+	}
+	if err := i.fullText.Index(record, data); err != nil {
+		// Keep both indexes consistent, nothing else can be done if the rollback fails.
+		_ = i.exactMatchSearchIndex.Remove(record, data)
+		return err
+	}
+	return nil
+}
+
+func (i *dualIndex) Remove(record *api.MetaRecord, data string) error {
+	if err := i.exactMatchSearchIndex.Remove(record, data); err != nil {
+		return err
+	}
+	return i.fullText.Remove(record, data)
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexStrategies(t *testing.T) {
+	s := newTestStore(t,
+		WithIndexStrategy(api.SearchFieldTitle, IndexBoth),
+		WithIndexStrategy(api.SearchFieldCompany, IndexFullText))
+	search := func(term api.SearchTerm) (*SearchResult, error) {
+		return s.Search(api.SearchJoinMethodAND, []api.SearchTerm{term}, SearchOptions{Strict: true, Explain: true})
+	}
+
+	result, err := search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "Valid App 2"})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1, "fields indexed both ways should be matched exactly by default")
+	result, err = search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "app"})
+	require.NoError(t, err)
+	require.Empty(t, result.Records)
+	result, err = search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "app", Match: api.MatchModeFullText})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+	require.Equal(t, indexKindFullText, result.Explanation.Terms[0].Index)
+	result, err = search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "Valid App 1", Match: api.MatchModeExact})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+
+	result, err = search(api.SearchTerm{Field: api.SearchFieldCompany, Query: "upbound"})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	_, err = search(api.SearchTerm{Field: api.SearchFieldCompany, Query: "Upbound Inc.", Match: api.MatchModeExact})
+	require.Error(t, err, "full text fields don't support exact matches")
+	_, err = search(api.SearchTerm{Field: api.SearchFieldVersion, Query: "1.0.1", Match: api.MatchModeFullText})
+	require.Error(t, err, "exact fields don't support full text matches")
+
+	values, err := s.FieldValues(api.SearchFieldTitle)
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldValue{{Value: "Valid App 1", Count: 1}, {Value: "Valid App 2", Count: 1}}, values)
+
+	// Removing a record must remove it from both indexes.
+	result, err = search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "Valid App 1"})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(result.Records[0].ID, WriteOptions{}))
+	for _, match := range []api.MatchMode{api.MatchModeExact, api.MatchModeFullText} {
+		result, err = search(api.SearchTerm{Field: api.SearchFieldTitle, Query: "Valid App 2", Match: match})
+		require.NoError(t, err)
+		require.Len(t, result.Records, 1, "the %s index should only have the remaining record", match)
+	}
+}
+
+func TestIndexStrategyOptions(t *testing.T) {
+	_, err := New(WithIndexStrategy(api.SearchFieldLicense, IndexFullText))
+	require.ErrorIs(t, err, ErrInvalidIndexStrategy, "fields with specialized indexes can't be configured")
+	_, err = New(WithIndexStrategy(api.SearchFieldTitle, "fuzzy"))
+	require.ErrorIs(t, err, ErrInvalidIndexStrategy)
+	_, err = New(WithIndexStrategy(api.SearchFieldDescription, IndexExact), WithAnalyzer(api.SearchFieldDescription, AnalyzerEnglish))
+	require.ErrorIs(t, err, ErrNotFullText)
+
+	s, err := New(WithIndexStrategy(api.SearchFieldTitle, IndexBoth), WithAnalyzer(api.SearchFieldTitle, AnalyzerNgram))
+	require.NoError(t, err)
+	_, err = s.RegisterCustomField(api.CustomField{Name: "notes", Type: api.CustomFieldTypeString,
+		Index: api.CustomFieldIndexBoth})
+	require.NoError(t, err)
+	require.Equal(t, []api.FieldAnalyzer{
+		{Field: api.SearchFieldDescription, Analyzer: AnalyzerStandard},
+		{Field: "notes", Analyzer: AnalyzerStandard},
+		{Field: api.SearchFieldTitle, Analyzer: AnalyzerNgram},
+	}, s.FieldAnalyzers())
+}
+
+func TestSetAnalyzerBothStrategy(t *testing.T) {
+	s := newTestStore(t, WithIndexStrategy(api.SearchFieldTitle, IndexBoth))
+	_, err := s.SetAnalyzer(api.SearchFieldTitle, AnalyzerNgram)
+	require.NoError(t, err)
+
+	result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+		{Field: api.SearchFieldTitle, Query: "vali", Match: api.MatchModeFullText},
+		{Field: api.SearchFieldTitle, Query: "Valid App 1"},
+	}, SearchOptions{Strict: true})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1, "the exact index should be kept when the analyzer changes")
+}
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/common"
+)
+
+var ErrSubscriptionNotFound = errors.New("a subscription with the provided id does not exist")
+
+// SubscriptionEvent is delivered to a subscription when a created or updated record matches its query.
+type SubscriptionEvent struct {
+	// Seq identifies the event within the subscription and is used to resume streams.
+	Seq    uint64
+	Record *api.MetaRecord
+}
+
+// subscription is a registered search request with the log of records that matched it.
+type subscription struct {
+	api.Subscription
+	log *eventLog
+	// done is closed when the subscription is deleted.
+	done chan struct{}
+}
+
+// subscriptions holds all the registered subscriptions.
+// It has its own lock because subscriptions are registered and read without interacting
+// with the indexes, they.re only evaluated by writes while holding the store lock.
+type subscriptions struct {
+	mu   sync.RWMutex
+	byID map[string]*subscription
+}
+
+// CreateSubscription registers a search request that is evaluated against every created or updated record.
+// The request is expected to be validated by the caller.
+func (s *Store) CreateSubscription(req api.SearchRequest) (api.Subscription, error) {
+	id, err := common.NewID()
+	if err != nil {
+		return api.Subscription{}, err
+	}
+
+	sub := &subscription{
+		Subscription: api.Subscription{
+			ID:        id,
+			Request:   req,
+			CreatedAt: time.Now().UTC(),
+		},
+		log:  newEventLog(defaultEventLogCapacity),
+		done: make(chan struct{}),
+	}
+
+	s.subscriptions.mu.Lock()
+	defer s.subscriptions.mu.Unlock()
+	s.subscriptions.byID[id] = sub
+	return sub.Subscription, nil
+}
+
+// DeleteSubscription removes a subscription and ends all of its event streams.
+func (s *Store) DeleteSubscription(id string) error {
+	s.subscriptions.mu.Lock()
+	defer s.subscriptions.mu.Unlock()
+
+	sub, ok := s.subscriptions.byID[id]
+	if !ok {
+		return ErrSubscriptionNotFound
+	}
+	close(sub.done)
+	delete(s.subscriptions.byID, id)
+	return nil
+}
+
+func (s *Store) Subscription(id string) (api.Subscription, error) {
+	s.subscriptions.mu.RLock()
+	defer s.subscriptions.mu.RUnlock()
+
+	sub, ok := s.subscriptions.byID[id]
+	if !ok {
+		return api.Subscription{}, ErrSubscriptionNotFound
+	}
+	return sub.Subscription, nil
+}
+
+// Subscriptions returns all the registered subscriptions sorted by creation.
+func (s *Store) Subscriptions() []api.Subscription {
+	s.subscriptions.mu.RLock()
+	defer s.subscriptions.mu.RUnlock()
+
+	subs := []api.Subscription{}
+	for _, sub := range s.subscriptions.byID {
+		subs = append(subs, sub.Subscription)
+	}
+	// Ids are ulids so they sort by creation time.
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].ID < subs[j].ID
+	})
+	return subs
+}
+
+// SubscriptionEvents returns the retained events of a subscription with a sequence
+// number greater than seq, a channel that is closed when a newer event is available and
+// a channel that is closed when the subscription is deleted.
+func (s *Store) SubscriptionEvents(id string, seq uint64) ([]SubscriptionEvent,
+	<-chan struct{}, <-chan struct{}, error) {
+	s.subscriptions.mu.RLock()
+	sub, ok := s.subscriptions.byID[id]
+	s.subscriptions.mu.RUnlock()
+	if !ok {
+		return nil, nil, nil, ErrSubscriptionNotFound
+	}
+
+	logEvents, next := sub.log.since(seq)
+	events := []SubscriptionEvent{}
+	for _, event := range logEvents {
+		events = append(events, SubscriptionEvent{Seq: event.Seq, Record: event.Payload.(*api.MetaRecord)})
+	}
+	return events, next, sub.done, nil
+}
+
+// notifySubscriptions evaluates every subscription against a record that was just created
+// or updated and records an event for the ones that match it. Only the record is evaluated,
+// the caller must hold the store write lock.
+func (s *Store) notifySubscriptions(record *api.MetaRecord) {
+	s.subscriptions.mu.RLock()
+	defer s.subscriptions.mu.RUnlock()
+
+	if len(s.subscriptions.byID) == 0 {
+		return
+	}
+	// The record was just indexed so its values can't fail to be computed.
+	values, _ := s.indexValues(record)
+	for _, sub := range s.subscriptions.byID {
+		if s.matches(sub.Request, record, values) {
+			sub.log.append(record)
+		}
+	}
+}
+
+// indexValues returns the values a record is indexed with grouped by field.
+// The caller must hold the store lock.
+func (s *Store) indexValues(record *api.MetaRecord) (map[api.SearchField][]string, error) {
+	entries, err := s.indexEntries(record)
+	if err != nil {
+		return nil, err
+	}
+	values := map[api.SearchField][]string{}
+	for _, entry := range entries {
+		values[entry.field] = append(values[entry.field], entry.value)
+	}
+	return values, nil
+}
+
+// matches reports whether a search request would return a record, following the same
+// rules as search: strict requests fail as a whole when a term can't be resolved and
+// otherwise the term is skipped. The caller must hold the store lock.
+func (s *Store) matches(req api.SearchRequest, record *api.MetaRecord,
+	values map[api.SearchField][]string) bool {
+	opts := OptionsFromRequest(req)
+	joinedTerms, matchedTerms := 0, 0
+	for _, term := range req.SearchTerms {
+		index, err := s.termIndex(term)
+		matched := false
+		if err == nil {
+			matched, err = index.Matches(record, values[term.Field], term.Query)
+		}
+		if err != nil {
+			if opts.Strict {
+				return false
+			}
+			continue
+		}
+		joinedTerms += 1
+		if matched {
+			matchedTerms += 1
+		}
+	}
+
+	switch req.JoinMethod {
+	case api.SearchJoinMethodOR:
+		if matchedTerms == 0 {
+			return false
+		}
+	case api.SearchJoinMethodAND:
+		if joinedTerms == 0 || matchedTerms != joinedTerms {
+			return false
+		}
+	default:
+		return false
+	}
+	if !opts.LatestOnly {
+		return true
+	}
+
+	// The record is collapsed if a newer version of its application matches too.
+	req.LatestOnly = false
+	for _, other := range s.appVersions(record.AppSlug()) {
+		if other == record || !isNewerVersion(other, record) {
+			continue
+		}
+		otherValues, err := s.indexValues(other)
+		if err == nil && s.matches(req, other, otherValues) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptions(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	sub, err := s.CreateSubscription(api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodOR,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchFieldCompany, Query: "Random Inc."}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []api.Subscription{sub}, s.Subscriptions())
+
+	events, next, done, err := s.SubscriptionEvents(sub.ID, 0)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	fis, err := os.ReadDir(validDir)
+	require.NoError(t, err, "Test dir for valid records doesn't exist")
+	for _, fi := range fis {
+		data, err := os.ReadFile(filepath.Join(validDir, fi.Name()))
+		require.NoError(t, err)
+		_, err = s.Append(data, WriteOptions{})
+		require.NoError(t, err)
+	}
+
+	select {
+	case <-next:
+	default:
+		require.Fail(t, "subscription readers should be notified of matches")
+	}
+
+	events, _, _, err = s.SubscriptionEvents(sub.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1, "only matching records should be delivered")
+	require.Equal(t, uint64(1), events[0].Seq)
+	require.Equal(t, "Valid App 1", events[0].Record.Title)
+
+	events, _, _, err = s.SubscriptionEvents(sub.ID, 1)
+	require.NoError(t, err)
+	require.Empty(t, events, "events up to the provided sequence number should be skipped")
+
+	require.NoError(t, s.DeleteSubscription(sub.ID))
+	select {
+	case <-done:
+	default:
+		require.Fail(t, "deleting a subscription should end its streams")
+	}
+	_, _, _, err = s.SubscriptionEvents(sub.ID, 0)
+	require.Equal(t, ErrSubscriptionNotFound, err)
+	require.Equal(t, ErrSubscriptionNotFound, s.DeleteSubscription(sub.ID))
+}
+
+func TestSubscriptionUpdates(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	subscribe := func(req api.SearchRequest) string {
+		sub, err := s.CreateSubscription(req)
+		require.NoError(t, err)
+		return sub.ID
+	}
+	company := subscribe(api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodOR,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchFieldCompany, Query: "Acme Corp"}},
+	})
+	description := subscribe(api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodAND,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchFieldDescription, Query: "widgets"}},
+	})
+	latest := subscribe(api.SearchRequest{
+		JoinMethod:  api.SearchJoinMethodAND,
+		SearchTerms: []api.SearchTerm{{Field: api.SearchFieldTitle, Query: "Valid App 1"}},
+		LatestOnly:  true,
+	})
+	strict := subscribe(api.SearchRequest{
+		JoinMethod: api.SearchJoinMethodOR,
+		SearchTerms: []api.SearchTerm{
+			{Field: api.SearchFieldTitle, Query: "Valid App 1"},
+			{Field: api.SearchFieldMaintainer, Query: "not a maintainer"},
+		},
+		Mode: api.SearchModeStrict,
+	})
+	matches := func(id string) []*api.MetaRecord {
+		events, _, _, err := s.SubscriptionEvents(id, 0)
+		require.NoError(t, err)
+		records := []*api.MetaRecord{}
+		for _, event := range events {
+			records = append(records, event.Record)
+		}
+		return records
+	}
+
+	created, err := s.Append(testRecord(t, nil), WriteOptions{})
+	require.NoError(t, err)
+	require.Empty(t, matches(company))
+	require.Equal(t, []*api.MetaRecord{created}, matches(latest))
+
+	updated, err := s.Update(created.ID, testRecord(t, fields{"company": "Acme Corp"}), WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []*api.MetaRecord{updated}, matches(company), "updates that start matching should be delivered")
+	require.Equal(t, []*api.MetaRecord{created, updated}, matches(latest))
+
+	_, err = s.Append(testRecord(t, fields{"version": "0.0.0"}), WriteOptions{})
+	require.NoError(t, err)
+	require.Len(t, matches(latest), 2, "versions collapsed by a newer matching version should not be delivered")
+
+	upserted, replaced, err := s.Upsert(testRecord(t, fields{
+		"company":     "Acme Corp",
+		"description": "Manages widgets.",
+	}), WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, replaced)
+	require.Equal(t, []*api.MetaRecord{updated, upserted}, matches(company))
+	require.Equal(t, []*api.MetaRecord{upserted}, matches(description))
+	require.Empty(t, matches(strict), "strict requests with terms that can't be resolved never match")
+}
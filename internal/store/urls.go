@@ -0,0 +1,201 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/forge"
+)
+
+var (
+	ErrURLNotAllowed  = errors.New("the url is not allowed")
+	ErrInvalidURLRule = errors.New("the url rule is invalid")
+)
+
+// urlFields are the fields URL rules can be set for, in the order they are checked.
+var urlFields = []api.SearchField{api.SearchFieldWebsite, api.SearchFieldSource}
+
+// URLRule restricts the URLs a field accepts on top of the generic url validation.
+type URLRule struct {
+	// Schemes are the allowed schemes, e.g. https. Empty allows any scheme.
+	Schemes []string
+	// Hosts are the allowed hosts, entries starting with "*." match any subdomain of the
+	// rest of the entry. Empty allows any host.
+	Hosts []string
+}
+
+func defaultURLRules() map[api.SearchField]URLRule {
+	web := URLRule{Schemes: []string{"http", "https"}}
+	return map[api.SearchField]URLRule{
+		api.SearchFieldWebsite: web,
+		api.SearchFieldSource:  web,
+	}
+}
+
+func validateURLRules(rules map[api.SearchField]URLRule) error {
+	for field := range rules {
+		if field != api.SearchFieldWebsite && field != api.SearchFieldSource {
+			return fmt.Errorf("%w: %s is not a url field", ErrInvalidURLRule, field)
+		}
+	}
+	return nil
+}
+
+// check returns the reason a URL doesn't satisfy the rule, nil if it does.
+func (r URLRule) check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if len(r.Schemes) > 0 && !containsFold(r.Schemes, u.Scheme) {
+		return fmt.Errorf("the %s scheme is not allowed", u.Scheme)
+	}
+	if len(r.Hosts) > 0 && !r.allowsHost(strings.ToLower(u.Hostname())) {
+		return fmt.Errorf("the host %s is not allowed", u.Hostname())
+	}
+	return nil
+}
+
+// pattern returns a regular expression that approximates check for the JSON schema, empty if
+// the rule allows any url.
+func (r URLRule) pattern() string {
+	if len(r.Schemes) == 0 && len(r.Hosts) == 0 {
+		return ""
+	}
+	scheme := `[A-Za-z][A-Za-z0-9+.-]*`
+	if len(r.Schemes) > 0 {
+		scheme = alternatives(r.Schemes, caseless)
+	}
+	if len(r.Hosts) == 0 {
+		return "^" + scheme + ":"
+	}
+	host := alternatives(r.Hosts, func(h string) string {
+		if suffix := strings.TrimPrefix(h, "*"); suffix != h {
+			return `[^/?#@:]*` + caseless(suffix)
+		}
+		return caseless(h)
+	})
+	// The user info and the port are not part of the host.
+	return "^" + scheme + `://([^/?#@]*@)?` + host + `(:[0-9]*)?([/?#]|$)`
+}
+
+// alternatives joins the patterns of values into a group that matches any of them.
+func alternatives(values []string, pattern func(string) string) string {
+	patterns := make([]string, 0, len(values))
+	for _, value := range values {
+		patterns = append(patterns, pattern(value))
+	}
+	return "(" + strings.Join(patterns, "|") + ")"
+}
+
+// caseless returns a pattern that matches s ignoring case without flags, which are not
+// portable across JSON schema implementations.
+func caseless(s string) string {
+	b := strings.Builder{}
+	for _, c := range s {
+		if upper, lower := unicode.ToUpper(c), unicode.ToLower(c); upper != lower {
+			b.WriteString("[" + string(upper) + string(lower) + "]")
+		} else {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+func (r URLRule) allowsHost(host string) bool {
+	for _, allowed := range r.Hosts {
+		allowed = strings.ToLower(allowed)
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURLRules rejects records with URLs that don't satisfy the rules of their field.
+func (s *Store) checkURLRules(record *api.MetaRecord) error {
+	for _, field := range urlFields {
+		rule, ok := s.urlRules[field]
+		if !ok {
+			continue
+		}
+		value, _ := record.FieldValueFromSearchField(field)
+		if err := rule.check(value); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrURLNotAllowed, field, err)
+		}
+	}
+	return nil
+}
+
+// caseInsensitiveIndex is an exact match index that ignores the case of the values.
+type caseInsensitiveIndex struct {
+	exactMatchSearchIndex
+}
+
+func newCaseInsensitiveIndex() *caseInsensitiveIndex {
+	return &caseInsensitiveIndex{exactMatchSearchIndex{mapping: map[string][]*api.MetaRecord{}}}
+}
+
+func (i *caseInsensitiveIndex) Index(record *api.MetaRecord, data string) error {
+	return i.exactMatchSearchIndex.Index(record, strings.ToLower(data))
+}
+
+func (i *caseInsensitiveIndex) Remove(record *api.MetaRecord, data string) error {
+	return i.exactMatchSearchIndex.Remove(record, strings.ToLower(data))
+}
+
+func (i *caseInsensitiveIndex) Search(term string) ([]*api.MetaRecord, error) {
+	return i.exactMatchSearchIndex.Search(i.NormalizeQuery(term))
+}
+
+func (i *caseInsensitiveIndex) Matches(_ *api.MetaRecord, values []string, term string) (bool, error) {
+	if term == "" {
+		return false, errors.New("must provide a valid search term")
+	}
+	term = i.NormalizeQuery(term)
+	for _, value := range values {
+		if strings.ToLower(value) == term {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (i *caseInsensitiveIndex) NormalizeQuery(term string) string {
+	return strings.ToLower(term)
+}
+
+// sourceValues returns the values of the fields derived from the source of a record: the
+// host of the source url and the owner of the repository if it is hosted in a known forge.
+func sourceValues(record *api.MetaRecord, field api.SearchField) []string {
+	repository, isForge := forge.Parse(record.Source)
+	switch {
+	case isForge && field == api.SearchFieldSourceHost:
+		return []string{repository.Host}
+	case isForge && field == api.SearchFieldSourceOwner:
+		return []string{repository.Owner}
+	case field == api.SearchFieldSourceHost:
+		if u, err := url.Parse(record.Source); err == nil && u.Hostname() != "" {
+			return []string{u.Hostname()}
+		}
+	}
+	return nil
+}
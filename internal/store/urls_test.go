@@ -0,0 +1,59 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLRuleCheck(t *testing.T) {
+	rule := URLRule{Schemes: []string{"https"}, Hosts: []string{"github.com", "*.example.com"}}
+	require.NoError(t, rule.check("https://github.com/upbound/repo"))
+	require.NoError(t, rule.check("HTTPS://GitHub.com/upbound/repo"))
+	require.NoError(t, rule.check("https://git.example.com/repo"))
+	require.Error(t, rule.check("ftp://github.com/upbound/repo"))
+	require.Error(t, rule.check("https://example.com/repo"))
+	require.Error(t, rule.check("https://gitlab.com/group/repo"))
+	require.NoError(t, URLRule{}.check("ftp://x"), "the zero rule should allow any url")
+}
+
+func TestURLRules(t *testing.T) {
+	s := newTestStore(t)
+	_, err := s.Append(testRecord(t, fields{"version": "1.0.0", "website": "ftp://x", "source": "https://github.com/random/repo"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrURLNotAllowed, "only http and https should be allowed by default")
+	_, err = s.Append(testRecord(t, fields{"version": "1.0.0", "website": "https://website.com", "source": "ftp://x"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrURLNotAllowed)
+
+	s, err = New(WithURLRule(api.SearchFieldSource, URLRule{Hosts: []string{"github.com", "gitlab.com"}}))
+	require.NoError(t, err)
+	_, err = s.Append(testRecord(t, fields{"version": "1.0.0", "website": "https://website.com", "source": "https://bitbucket.org/team/repo"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrURLNotAllowed)
+	_, err = s.Append(testRecord(t, fields{"version": "1.0.0", "website": "https://website.com", "source": "git://github.com/team/repo"}), WriteOptions{})
+	require.NoError(t, err, "the source rule should replace the default one")
+	_, err = s.Append(testRecord(t, fields{"version": "2.0.0", "website": "ftp://x", "source": "https://github.com/team/repo"}), WriteOptions{})
+	require.ErrorIs(t, err, ErrURLNotAllowed, "the website rule should be kept")
+
+	_, err = New(WithURLRule(api.SearchFieldTitle, URLRule{}))
+	require.ErrorIs(t, err, ErrInvalidURLRule)
+}
+
+func TestSourceSearchFields(t *testing.T) {
+	s := newTestStore(t)
+	gitlab, err := s.Append(testRecord(t, fields{"version": "1.0.0", "source": "https://gitlab.com/Platform/tools/app.git"}), WriteOptions{})
+	require.NoError(t, err)
+	selfHosted, err := s.Append(testRecord(t, fields{"version": "2.0.0", "source": "https://git.example.com/platform/app"}), WriteOptions{})
+	require.NoError(t, err)
+
+	search := func(field api.SearchField, query string) []*api.MetaRecord {
+		result, err := s.Search(api.SearchJoinMethodAND, []api.SearchTerm{
+			{Field: field, Query: query},
+		}, SearchOptions{Strict: true})
+		require.NoError(t, err, query)
+		return result.Records
+	}
+	require.Equal(t, []*api.MetaRecord{gitlab}, search(api.SearchFieldSourceHost, "GitLab.com"))
+	require.Equal(t, []*api.MetaRecord{gitlab}, search(api.SearchFieldSourceOwner, "platform/tools"))
+	require.Equal(t, []*api.MetaRecord{selfHosted}, search(api.SearchFieldSourceHost, "git.example.com"))
+	require.Empty(t, search(api.SearchFieldSourceOwner, "platform"), "owners are only known for forges")
+}
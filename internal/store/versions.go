@@ -0,0 +1,204 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-yaml"
+)
+
+var ErrUnsupportedAPIVersion = errors.New("the apiVersion of the record is not supported")
+
+// schemaVersion is a previous version of the records schema. Documents of a previous version
+// are validated with its own rules and upgraded one version at a time until they reach the
+// current one, which is the one api.MetaRecord represents.
+type schemaVersion struct {
+	name string
+	// validate checks a raw document of this version.
+	validate func(rawRecord []byte) error
+	// upgrade converts a valid document of this version into a document of the next one.
+	upgrade func(doc yaml.MapSlice) (yaml.MapSlice, error)
+	// jsonSchema converts the JSON schema of the next version into the one of this version.
+	jsonSchema func(next map[string]interface{}) map[string]interface{}
+	next       string
+}
+
+// schemaVersions holds every previous version of the schema by name.
+var schemaVersions = map[string]schemaVersion{
+	api.APIVersionV1Alpha1: {
+		name:       api.APIVersionV1Alpha1,
+		validate:   validateV1Alpha1,
+		upgrade:    upgradeV1Alpha1,
+		jsonSchema: jsonSchemaV1Alpha1,
+		next:       api.APIVersionV1,
+	},
+}
+
+// SchemaVersions returns the supported versions of the records schema from the oldest to
+// the current one.
+func SchemaVersions() []string {
+	return []string{api.APIVersionV1Alpha1, api.APIVersionV1}
+}
+
+// documentAPIVersion returns the apiVersion of a raw document, the current version if it has none.
+func documentAPIVersion(rawRecord []byte) (string, error) {
+	var header struct {
+		APIVersion interface{} `yaml:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(rawRecord, &header); err != nil {
+		return "", ErrUnparsable
+	}
+	if header.APIVersion == nil {
+		return api.APIVersionV1, nil
+	}
+	version, ok := header.APIVersion.(string)
+	if !ok {
+		return "", ErrUnsupportedAPIVersion
+	}
+	return version, nil
+}
+
+// upgradePath returns the previous versions a document of a version goes through until it
+// reaches the current one, starting with its own.
+func upgradePath(version string) ([]schemaVersion, error) {
+	path := []schemaVersion{}
+	for version != api.APIVersionV1 {
+		schema, ok := schemaVersions[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedAPIVersion, version)
+		}
+		path = append(path, schema)
+		version = schema.next
+	}
+	return path, nil
+}
+
+// upgradeDocument converts a raw document of a previous schema version into a document of
+// the current version, validating it with the rules of every version it goes through.
+func upgradeDocument(rawRecord []byte, version string) ([]byte, error) {
+	upgrades, err := upgradePath(version)
+	if err != nil {
+		return nil, err
+	}
+	for _, schema := range upgrades {
+		if err := schema.validate(rawRecord); err != nil {
+			return nil, fmt.Errorf("%s: %w", schema.name, err)
+		}
+
+		var doc yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions(rawRecord, &doc, yaml.UseOrderedMap()); err != nil {
+			return nil, ErrUnparsable
+		}
+		doc, err := schema.upgrade(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", schema.name, err)
+		}
+		doc = setDocumentField(doc, "apiVersion", schema.next)
+
+		rawRecord, err = yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rawRecord, nil
+}
+
+// setDocumentField replaces the value of a top level field or adds it at the beginning.
+func setDocumentField(doc yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range doc {
+		if item.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(yaml.MapSlice{{Key: key, Value: value}}, doc...)
+}
+
+// recordV1Alpha1 is the draft format the catalogue started with. Maintainers were written as
+// "Name <email>" and the source repository was named repository.
+type recordV1Alpha1 struct {
+	APIVersion  string   `yaml:"apiVersion" validate:"required"`
+	Title       string   `yaml:"title" validate:"required"`
+	Version     string   `yaml:"version" validate:"required"`
+	Maintainers []string `yaml:"maintainers" validate:"required,gt=0,dive,required"`
+	Company     string   `yaml:"company" validate:"required"`
+	Website     string   `yaml:"website" validate:"required,url"`
+	Repository  string   `yaml:"repository" validate:"required,url"`
+	License     string   `yaml:"license" validate:"required"`
+	Description string   `yaml:"description" validate:"required"`
+}
+
+func validateV1Alpha1(rawRecord []byte) error {
+	var r recordV1Alpha1
+	if err := yaml.Unmarshal(rawRecord, &r); err != nil {
+		return ErrUnparsable
+	}
+	if err := validate.Struct(r); err != nil {
+		return fmt.Errorf("the following field(s) are missing or invalid: %s",
+			strings.Join(schemaErrorFields(err.(validator.ValidationErrors)), ","))
+	}
+	for _, maintainer := range r.Maintainers {
+		if _, err := mail.ParseAddress(maintainer); err != nil {
+			return fmt.Errorf("maintainers must have the form \"Name <email>\": %q", maintainer)
+		}
+	}
+	return nil
+}
+
+func upgradeV1Alpha1(doc yaml.MapSlice) (yaml.MapSlice, error) {
+	for i, item := range doc {
+		switch item.Key {
+		case "repository":
+			doc[i].Key = "source"
+		case "maintainers":
+			entries, ok := item.Value.([]interface{})
+			if !ok {
+				return nil, errors.New("maintainers must be a list")
+			}
+			maintainers := []interface{}{}
+			for _, entry := range entries {
+				// The addresses were checked by validateV1Alpha1.
+				address, err := mail.ParseAddress(fmt.Sprint(entry))
+				if err != nil {
+					return nil, err
+				}
+				maintainers = append(maintainers, yaml.MapSlice{
+					{Key: "name", Value: address.Name},
+					{Key: "email", Value: address.Address},
+				})
+			}
+			doc[i].Value = maintainers
+		}
+	}
+	return doc, nil
+}
+
+// v1alpha1MaintainerPattern approximates the addresses accepted by mail.ParseAddress, either
+// "Name <email>" or a bare email.
+const v1alpha1MaintainerPattern = `^([^<>]*<[^<>\s@]+@[^<>\s]+>|[^<>\s@]+@[^<>\s]+)$`
+
+func jsonSchemaV1Alpha1(next map[string]interface{}) map[string]interface{} {
+	properties := next["properties"].(map[string]interface{})
+	properties["apiVersion"] = map[string]interface{}{"type": "string", "enum": []string{api.APIVersionV1Alpha1}}
+	properties["maintainers"] = map[string]interface{}{
+		"type":     "array",
+		"minItems": 1,
+		"items":    map[string]interface{}{"type": "string", "pattern": v1alpha1MaintainerPattern},
+	}
+	properties["repository"] = properties["source"]
+	delete(properties, "source")
+
+	required := []string{"apiVersion"}
+	for _, name := range next["required"].([]string) {
+		if name == "source" {
+			name = "repository"
+		}
+		required = append(required, name)
+	}
+	next["required"] = required
+	return next
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+)
+
+const legacyDir = "testdata/legacy"
+
+func TestUpgradeV1Alpha1(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(legacyDir, "v1alpha1.yaml"))
+	require.NoError(t, err)
+
+	record, err := newRecord(data)
+	require.NoError(t, err)
+	require.Equal(t, api.APIVersionV1, record.APIVersion, "upgraded records should have the current version")
+	require.Equal(t, "https://github.com/random/legacy", record.Source)
+	require.Len(t, record.Maintainers, 2)
+	require.Equal(t, "First Maintainer", record.Maintainers[0].Name)
+	require.Equal(t, "first@example.com", record.Maintainers[0].Email)
+	require.Equal(t, map[string]interface{}{"supportTier": "gold"}, record.Custom,
+		"fields unknown to the migration should be kept")
+
+	raw, err := yaml.Marshal(record)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(raw), "apiVersion: v1\ntitle: Legacy App\n"))
+
+	// Every version is validated with its own rules.
+	_, err = newRecord([]byte(strings.Replace(string(data), "<first@example.com>", "first@example.com", 1)))
+	require.Error(t, err)
+	_, err = newRecord([]byte(strings.Replace(string(data), "repository:", "source:", 1)))
+	require.Error(t, err, "v1alpha1 documents must use repository")
+	_, err = newRecord([]byte(strings.Replace(string(data), "v1alpha1", "v2", 1)))
+	require.ErrorIs(t, err, ErrUnsupportedAPIVersion)
+}
+
+func TestCurrentAPIVersion(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(validDir, "valid1.yaml"))
+	require.NoError(t, err)
+
+	record, err := newRecord(data)
+	require.NoError(t, err)
+	require.Empty(t, record.APIVersion, "records without apiVersion are of the current version")
+
+	record, err = newRecord([]byte("apiVersion: v1\n" + string(data)))
+	require.NoError(t, err)
+	require.Equal(t, api.APIVersionV1, record.APIVersion)
+}
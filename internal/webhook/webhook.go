@@ -0,0 +1,384 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/common"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256=".
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader holds the operation that triggered the delivery.
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader holds an id that is the same for all the attempts of a delivery.
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	defaultMaxDeadLetters = 1000
+)
+
+var (
+	ErrWebhookNotFound = errors.New("a webhook with the provided id does not exist")
+	ErrInvalidURL      = errors.New("webhook urls must be absolute http or https urls")
+	ErrMissingSecret   = errors.New("a secret must be provided to sign the webhook payloads")
+)
+
+// ChangeSource is the feed of record changes the dispatcher delivers.
+// It is implemented by *store.Store.
+type ChangeSource interface {
+	Changes(seq uint64) ([]api.Change, <-chan struct{}, error)
+	LastChange() uint64
+}
+
+// EncodeFunc builds the body of the request delivered for a change.
+type EncodeFunc func(api.Change) ([]byte, error)
+
+type Config struct {
+	// Number of goroutines delivering requests concurrently.
+	Workers int
+	// Total number of attempts of a delivery before it is dead lettered.
+	MaxAttempts int
+	// Delay before the first retry, doubled on every subsequent retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout of every delivery request.
+	Timeout time.Duration
+	// Maximum number of dead letters kept, the oldest ones are discarded first.
+	// Zero uses the default.
+	MaxDeadLetters int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Workers:        4,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        10 * time.Second,
+		MaxDeadLetters: defaultMaxDeadLetters,
+	}
+}
+
+// webhook is a registered webhook along with its secret, which is never returned by the api.
+type webhook struct {
+	api.Webhook
+	secret string
+}
+
+// delivery is a single payload that has to be sent to a webhook.
+type delivery struct {
+	id        string
+	webhookID string
+	url       string
+	secret    string
+	op        api.ChangeOp
+	changeSeq uint64
+	payload   []byte
+	attempts  int
+}
+
+// Dispatcher follows a change feed and delivers every change to the registered webhooks.
+type Dispatcher struct {
+	cfg     Config
+	changes ChangeSource
+	encode  EncodeFunc
+	client  *http.Client
+
+	mu          sync.RWMutex
+	webhooks    map[string]*webhook
+	deadLetters []api.DeadLetter
+
+	queue chan *delivery
+}
+
+func NewDispatcher(cfg Config, changes ChangeSource, encode EncodeFunc) *Dispatcher {
+	if cfg.MaxDeadLetters <= 0 {
+		cfg.MaxDeadLetters = defaultMaxDeadLetters
+	}
+	return &Dispatcher{
+		cfg:      cfg,
+		changes:  changes,
+		encode:   encode,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		webhooks: map[string]*webhook{},
+		queue:    make(chan *delivery, 100),
+	}
+}
+
+// Start launches the background workers, they run until ctx is done.
+// Only changes made after Start is called are delivered.
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.follow(ctx, d.changes.LastChange())
+	for i := 0; i < d.cfg.Workers; i++ {
+		go d.work(ctx)
+	}
+}
+
+// Register adds a webhook that is notified of the provided operations, all of them if empty.
+func (d *Dispatcher) Register(rawURL string, secret string, events []api.ChangeOp) (api.Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return api.Webhook{}, ErrInvalidURL
+	}
+	if secret == "" {
+		return api.Webhook{}, ErrMissingSecret
+	}
+	for _, op := range events {
+		if err := op.IsValid(); err != nil {
+			return api.Webhook{}, err
+		}
+	}
+	if events == nil {
+		events = []api.ChangeOp{}
+	}
+
+	id, err := common.NewID()
+	if err != nil {
+		return api.Webhook{}, err
+	}
+
+	hook := &webhook{
+		Webhook: api.Webhook{
+			ID:        id,
+			URL:       rawURL,
+			Events:    events,
+			CreatedAt: time.Now().UTC(),
+		},
+		secret: secret,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.webhooks[id] = hook
+	return hook.Webhook, nil
+}
+
+// Unregister removes a webhook, pending retries for it are dropped.
+func (d *Dispatcher) Unregister(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(d.webhooks, id)
+	return nil
+}
+
+func (d *Dispatcher) Webhook(id string) (api.Webhook, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	hook, ok := d.webhooks[id]
+	if !ok {
+		return api.Webhook{}, ErrWebhookNotFound
+	}
+	return hook.Webhook, nil
+}
+
+// Webhooks returns all the registered webhooks sorted by creation.
+func (d *Dispatcher) Webhooks() []api.Webhook {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	hooks := []api.Webhook{}
+	for _, hook := range d.webhooks {
+		hooks = append(hooks, hook.Webhook)
+	}
+	// Ids are ulids so they sort by creation time.
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].ID < hooks[j].ID
+	})
+	return hooks
+}
+
+// DeadLetters returns the deliveries that failed after all their attempts, oldest first.
+func (d *Dispatcher) DeadLetters() []api.DeadLetter {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return append([]api.DeadLetter{}, d.deadLetters...)
+}
+
+// Sign returns the value of the signature header for a payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// follow reads the change feed and queues a delivery for every webhook interested in a change.
+func (d *Dispatcher) follow(ctx context.Context, seq uint64) {
+	for {
+		changes, next, err := d.changes.Changes(seq)
+		if err != nil {
+			// The workers fell too far behind, skip to the current change so at least
+			// new changes are delivered.
+			last := d.changes.LastChange()
+			log.Printf("webhook: changes %d to %d were discarded before being delivered: %s", seq+1, last, err)
+			seq = last
+			continue
+		}
+
+		for _, change := range changes {
+			d.dispatch(ctx, change)
+			seq = change.Seq
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-next:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, change api.Change) {
+	payload, err := d.encode(change)
+	if err != nil {
+		log.Printf("webhook: change %d could not be encoded: %s", change.Seq, err)
+		return
+	}
+
+	d.mu.RLock()
+	deliveries := []*delivery{}
+	for _, hook := range d.webhooks {
+		if !hook.wants(change.Op) {
+			continue
+		}
+		id, err := common.NewID()
+		if err != nil {
+			log.Printf("webhook: delivery id could not be created: %s", err)
+			continue
+		}
+		deliveries = append(deliveries, &delivery{
+			id:        id,
+			webhookID: hook.ID,
+			url:       hook.URL,
+			secret:    hook.secret,
+			op:        change.Op,
+			changeSeq: change.Seq,
+			payload:   payload,
+		})
+	}
+	d.mu.RUnlock()
+
+	for _, del := range deliveries {
+		select {
+		case <-ctx.Done():
+			return
+		case d.queue <- del:
+		}
+	}
+}
+
+func (h *webhook) wants(op api.ChangeOp) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, event := range h.Events {
+		if event == op {
+			return true
+		}
+	}
+	return false
+}
+
+// work delivers queued requests, scheduling retries with exponential backoff.
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case del := <-d.queue:
+			// The webhook could have been removed while the delivery was waiting for a retry.
+			if _, err := d.Webhook(del.webhookID); err != nil {
+				continue
+			}
+
+			del.attempts += 1
+			err := d.send(ctx, del)
+			if err == nil {
+				continue
+			}
+			if del.attempts >= d.cfg.MaxAttempts {
+				d.deadLetter(del, err)
+				continue
+			}
+			time.AfterFunc(d.backoff(del.attempts), func() {
+				select {
+				case <-ctx.Done():
+				case d.queue <- del:
+				}
+			})
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt after the provided number of attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// send performs a single delivery attempt, any non 2xx response is considered a failure.
+func (d *Dispatcher) send(ctx context.Context, del *delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.url, bytes.NewReader(del.payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(del.secret, del.payload))
+	req.Header.Set(EventHeader, string(del.op))
+	req.Header.Set(DeliveryHeader, del.id)
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
+
+func (d *Dispatcher) deadLetter(del *delivery, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadLetters = append(d.deadLetters, api.DeadLetter{
+		ID:        del.id,
+		WebhookID: del.webhookID,
+		URL:       del.url,
+		ChangeSeq: del.changeSeq,
+		Payload:   string(del.payload),
+		Attempts:  del.attempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now().UTC(),
+	})
+	if overflow := len(d.deadLetters) - d.cfg.MaxDeadLetters; overflow > 0 {
+		// Copy the kept dead letters so the discarded ones can be garbage collected.
+		d.deadLetters = append([]api.DeadLetter{}, d.deadLetters[overflow:]...)
+	}
+}
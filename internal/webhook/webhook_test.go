@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AYM1607/goAKSChallenge/api"
+	"github.com/AYM1607/goAKSChallenge/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validRecordFp = "../store/testdata/valid/valid1.yaml"
+	testSecret    = "secret"
+)
+
+var testConfig = Config{
+	Workers:        2,
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Timeout:        time.Second,
+}
+
+func encodeTestChange(change api.Change) ([]byte, error) {
+	return json.Marshal(&change)
+}
+
+// receiver records the requests it receives and fails the first n of them, n being failures.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, failures int) (*receiver, *httptest.Server) {
+	rcv := &receiver{failures: failures, received: make(chan struct{}, 100)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		rcv.mu.Lock()
+		rcv.requests = append(rcv.requests, r)
+		rcv.bodies = append(rcv.bodies, body)
+		fail := len(rcv.requests) <= rcv.failures
+		rcv.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		rcv.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return rcv, server
+}
+
+// wait blocks until the receiver gets n requests.
+func (rcv *receiver) wait(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-rcv.received:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the receiver did not get the expected requests")
+		}
+	}
+}
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, *store.Store) {
+	s, err := store.New()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	d := NewDispatcher(testConfig, s, encodeTestChange)
+	d.Start(ctx)
+	return d, s
+}
+
+func appendTestRecord(t *testing.T, s *store.Store) *api.MetaRecord {
+	data, err := os.ReadFile(validRecordFp)
+	require.NoError(t, err)
+	record, err := s.Append(data, store.WriteOptions{})
+	require.NoError(t, err)
+	return record
+}
+
+func TestRegister(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+
+	_, err := d.Register("ftp://example.com", testSecret, nil)
+	require.Equal(t, ErrInvalidURL, err)
+	_, err = d.Register("/relative", testSecret, nil)
+	require.Equal(t, ErrInvalidURL, err)
+	_, err = d.Register("https://example.com", "", nil)
+	require.Equal(t, ErrMissingSecret, err)
+	_, err = d.Register("https://example.com", testSecret, []api.ChangeOp{"upsert"})
+	require.Error(t, err, "only valid operations should be accepted")
+
+	hook, err := d.Register("https://example.com", testSecret, []api.ChangeOp{api.ChangeOpDelete})
+	require.NoError(t, err)
+	require.Equal(t, []api.Webhook{hook}, d.Webhooks())
+
+	require.NoError(t, d.Unregister(hook.ID))
+	_, err = d.Webhook(hook.ID)
+	require.Equal(t, ErrWebhookNotFound, err)
+	require.Equal(t, ErrWebhookNotFound, d.Unregister(hook.ID))
+}
+
+func TestDeliveryWithRetries(t *testing.T) {
+	d, s := newTestDispatcher(t)
+	rcv, server := newReceiver(t, 2)
+
+	_, err := d.Register(server.URL, testSecret, nil)
+	require.NoError(t, err)
+	// Webhooks that are not interested in the operation should not receive it.
+	_, err = d.Register(server.URL+"/deletes", testSecret, []api.ChangeOp{api.ChangeOpDelete})
+	require.NoError(t, err)
+
+	record := appendTestRecord(t, s)
+	rcv.wait(t, 3)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	require.Len(t, rcv.requests, 3, "the delivery should be retried until it succeeds")
+	for i, req := range rcv.requests {
+		require.Equal(t, "/", req.URL.Path)
+		require.Equal(t, Sign(testSecret, rcv.bodies[i]), req.Header.Get(SignatureHeader),
+			"every request should be signed with the webhook secret")
+		require.Equal(t, api.ChangeOpCreate, req.Header.Get(EventHeader))
+		require.Equal(t, rcv.requests[0].Header.Get(DeliveryHeader), req.Header.Get(DeliveryHeader),
+			"retries should keep the delivery id")
+	}
+
+	change := api.Change{}
+	require.NoError(t, json.Unmarshal(rcv.bodies[2], &change))
+	require.Equal(t, record.ID, change.RecordID)
+	require.Empty(t, d.DeadLetters())
+}
+
+func TestDeadLetters(t *testing.T) {
+	d, s := newTestDispatcher(t)
+	rcv, server := newReceiver(t, testConfig.MaxAttempts)
+
+	hook, err := d.Register(server.URL, testSecret, nil)
+	require.NoError(t, err)
+
+	appendTestRecord(t, s)
+	rcv.wait(t, testConfig.MaxAttempts)
+
+	// The dead letter is recorded right after the last response is received.
+	require.Eventually(t, func() bool {
+		return len(d.DeadLetters()) == 1
+	}, time.Second, time.Millisecond)
+
+	deadLetter := d.DeadLetters()[0]
+	require.Equal(t, hook.ID, deadLetter.WebhookID)
+	require.Equal(t, uint64(1), deadLetter.ChangeSeq)
+	require.Equal(t, testConfig.MaxAttempts, deadLetter.Attempts)
+	require.Contains(t, deadLetter.LastError, "503")
+}
+
+func TestDeadLettersCap(t *testing.T) {
+	s, err := store.New()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg := testConfig
+	cfg.MaxAttempts = 1
+	cfg.MaxDeadLetters = 2
+	// A single worker keeps the deliveries in order.
+	cfg.Workers = 1
+	d := NewDispatcher(cfg, s, encodeTestChange)
+	d.Start(ctx)
+	rcv, server := newReceiver(t, 3)
+
+	_, err = d.Register(server.URL, testSecret, nil)
+	require.NoError(t, err)
+	record := appendTestRecord(t, s)
+	require.NoError(t, s.Delete(record.ID, store.WriteOptions{}))
+	appendTestRecord(t, s)
+	rcv.wait(t, 3)
+
+	require.Eventually(t, func() bool {
+		letters := d.DeadLetters()
+		return len(letters) == 2 && letters[1].ChangeSeq == 3
+	}, time.Second, time.Millisecond, "only the latest dead letters should be kept")
+	require.Equal(t, uint64(2), d.DeadLetters()[0].ChangeSeq)
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}, nil, nil)
+	require.Equal(t, time.Second, d.backoff(1))
+	require.Equal(t, 2*time.Second, d.backoff(2))
+	require.Equal(t, 4*time.Second, d.backoff(3))
+	require.Equal(t, 5*time.Second, d.backoff(4), "the backoff should be capped")
+}